@@ -48,6 +48,124 @@ func (s *listIndentStyle) Set(v string) error {
 	return nil
 }
 
+type orderedListNumbering markdown.OrderedListNumbering
+
+var _ flag.Getter = (*orderedListNumbering)(nil)
+
+func (n *orderedListNumbering) Get() interface{} {
+	return markdown.OrderedListNumbering(*n)
+}
+
+func (n *orderedListNumbering) String() string {
+	switch markdown.OrderedListNumbering(*n) {
+	case markdown.OrderedListSequential:
+		return "sequential"
+	case markdown.OrderedListSame:
+		return "same"
+	case markdown.OrderedListPreserve:
+		return "preserve"
+	default:
+		return "invalid"
+	}
+}
+
+func (n *orderedListNumbering) Set(v string) error {
+	switch strings.TrimSpace(strings.ToLower(v)) {
+	case "sequential":
+		*n = orderedListNumbering(markdown.OrderedListSequential)
+	case "same":
+		*n = orderedListNumbering(markdown.OrderedListSame)
+	case "preserve":
+		*n = orderedListNumbering(markdown.OrderedListPreserve)
+	default:
+		return fmt.Errorf(`unrecognized numbering %q: valid values are "sequential", "same", and "preserve"`, v)
+	}
+	return nil
+}
+
+type orderedListDelimiter rune
+
+var _ flag.Getter = (*orderedListDelimiter)(nil)
+
+func (d *orderedListDelimiter) Get() interface{} {
+	return rune(*d)
+}
+
+func (d *orderedListDelimiter) String() string {
+	if *d == 0 {
+		return ""
+	}
+	return string(rune(*d))
+}
+
+func (d *orderedListDelimiter) Set(v string) error {
+	switch v = strings.TrimSpace(v); v {
+	case ".", ")":
+		*d = orderedListDelimiter(v[0])
+	default:
+		return fmt.Errorf(`unrecognized delimiter %q: valid values are "." and ")"`, v)
+	}
+	return nil
+}
+
+type referenceLinkMode markdown.ReferenceLinkMode
+
+var _ flag.Getter = (*referenceLinkMode)(nil)
+
+func (m *referenceLinkMode) Get() interface{} {
+	return markdown.ReferenceLinkMode(*m)
+}
+
+func (m *referenceLinkMode) String() string {
+	switch markdown.ReferenceLinkMode(*m) {
+	case markdown.ReferenceLinksNone:
+		return "none"
+	case markdown.ReferenceLinksNumbered:
+		return "numbered"
+	case markdown.ReferenceLinksSlug:
+		return "slug"
+	default:
+		return "invalid"
+	}
+}
+
+func (m *referenceLinkMode) Set(v string) error {
+	switch strings.TrimSpace(strings.ToLower(v)) {
+	case "none":
+		*m = referenceLinkMode(markdown.ReferenceLinksNone)
+	case "numbered":
+		*m = referenceLinkMode(markdown.ReferenceLinksNumbered)
+	case "slug":
+		*m = referenceLinkMode(markdown.ReferenceLinksSlug)
+	default:
+		return fmt.Errorf(`unrecognized mode %q: valid values are "none", "numbered", and "slug"`, v)
+	}
+	return nil
+}
+
+type bulletMarkers string
+
+var _ flag.Getter = (*bulletMarkers)(nil)
+
+func (m *bulletMarkers) Get() interface{} {
+	return string(*m)
+}
+
+func (m *bulletMarkers) String() string {
+	return string(*m)
+}
+
+func (m *bulletMarkers) Set(v string) error {
+	v = strings.TrimSpace(v)
+	for _, c := range v {
+		if !strings.ContainsRune("-*+", c) {
+			return fmt.Errorf(`unrecognized marker %q: valid markers are "-", "*", and "+"`, c)
+		}
+	}
+	*m = bulletMarkers(v)
+	return nil
+}
+
 func (cmd *mainCmd) registerFlags(flag *flag.FlagSet) {
 	flag.BoolVar(&cmd.list, "l", false, "list files whose formatting differs from markdownfmt's")
 	flag.BoolVar(&cmd.write, "w", false, "write result to (source) file instead of stdout")
@@ -55,7 +173,13 @@ func (cmd *mainCmd) registerFlags(flag *flag.FlagSet) {
 	flag.BoolVar(&cmd.underlineHeadings, "u", false, "write underline headings instead of hashes for levels 1 and 2")
 	flag.BoolVar(&cmd.softWraps, "soft-wraps", false, "wrap lines even on soft line breaks")
 	flag.BoolVar(&cmd.gofmt, "gofmt", false, "reformat Go source inside fenced code blocks")
+	flag.IntVar(&cmd.lineWidth, "width", 0, "reflow paragraphs to fit within the given number of columns (0 disables reflowing)")
+	flag.IntVar(&cmd.headingShift, "shift-headings", 0, "add the given number to the level of every heading (negative numbers promote headings)")
 	flag.Var((*listIndentStyle)(&cmd.listIndentStyle), "list-indent-style", `style for indenting items inside lists ("aligned" or "uniform")`)
+	flag.Var((*orderedListNumbering)(&cmd.listNumbering), "list-numbering", `numbering of ordered list items ("sequential", "same", or "preserve")`)
+	flag.Var((*orderedListDelimiter)(&cmd.listDelimiter), "list-delimiter", `delimiter after the numbers of ordered list items ("." or ")")`)
+	flag.Var((*referenceLinkMode)(&cmd.referenceLinks), "reference-links", `convert inline links to reference links labeled with numbers or slugs ("none", "numbered", or "slug")`)
+	flag.Var((*bulletMarkers)(&cmd.bulletMarkers), "bullet", `markers for bullet list items, rotated by nesting depth (e.g. "-" or "-*+")`)
 }
 
 func (cmd *mainCmd) report(err error) {
@@ -84,7 +208,12 @@ func (cmd *mainCmd) processFile(filename string, in io.Reader, out io.Writer) er
 		return err
 	}
 
-	opts := []markdown.Option{markdown.WithListIndentStyle(cmd.listIndentStyle)}
+	opts := []markdown.Option{
+		markdown.WithListIndentStyle(cmd.listIndentStyle),
+		markdown.WithOrderedListNumbering(cmd.listNumbering),
+		markdown.WithOrderedListDelimiter(cmd.listDelimiter),
+		markdown.WithReferenceLinks(cmd.referenceLinks),
+	}
 	if cmd.underlineHeadings {
 		opts = append(opts, markdown.WithUnderlineHeadings())
 	}
@@ -94,6 +223,15 @@ func (cmd *mainCmd) processFile(filename string, in io.Reader, out io.Writer) er
 	if cmd.gofmt {
 		opts = append(opts, markdown.WithCodeFormatters(markdown.GoCodeFormatter))
 	}
+	if cmd.lineWidth > 0 {
+		opts = append(opts, markdown.WithLineWidth(cmd.lineWidth))
+	}
+	if cmd.headingShift != 0 {
+		opts = append(opts, markdown.WithHeadingShift(cmd.headingShift))
+	}
+	if len(cmd.bulletMarkers) > 0 {
+		opts = append(opts, markdown.WithBulletMarker([]rune(cmd.bulletMarkers)...))
+	}
 	res, err := markdownfmt.Process(filename, src, opts...)
 	if err != nil {
 		return err
@@ -175,7 +313,13 @@ type mainCmd struct {
 	underlineHeadings bool
 	softWraps         bool
 	gofmt             bool
+	lineWidth         int
+	headingShift      int
 	listIndentStyle   markdown.ListIndentStyle
+	listNumbering     markdown.OrderedListNumbering
+	listDelimiter     rune
+	referenceLinks    markdown.ReferenceLinkMode
+	bulletMarkers     string
 }
 
 func (cmd *mainCmd) parseArgs(args []string) ([]string, error) {
@@ -43,6 +43,71 @@ func TestStdinStdout(t *testing.T) {
 			stdin:      "- foo\n  - bar\n- baz\n",
 			wantStdout: "- foo\n    - bar\n- baz\n",
 		},
+		{
+			desc:       "list-numbering",
+			args:       []string{"-list-numbering", "same"},
+			stdin:      "1. foo\n2. bar\n",
+			wantStdout: "1. foo\n1. bar\n",
+		},
+		{
+			desc:       "list-numbering/preserve",
+			args:       []string{"-list-numbering", "preserve"},
+			stdin:      "1. foo\n1. bar\n3. baz\n",
+			wantStdout: "1. foo\n1. bar\n3. baz\n",
+		},
+		{
+			desc:       "list-delimiter",
+			args:       []string{"-list-delimiter", ")"},
+			stdin:      "1. foo\n2. bar\n",
+			wantStdout: "1) foo\n2) bar\n",
+		},
+		{
+			desc:       "width",
+			args:       []string{"-width", "10"},
+			stdin:      "foo bar baz qux",
+			wantStdout: "foo bar\nbaz qux\n",
+		},
+		{
+			desc:       "shift-headings",
+			args:       []string{"-shift-headings", "1"},
+			stdin:      "# foo\n\n## bar\n",
+			wantStdout: "## foo\n\n### bar\n",
+		},
+		{
+			desc:       "shift-headings/promote",
+			args:       []string{"-shift-headings=-1"},
+			stdin:      "## foo\n\n### bar\n",
+			wantStdout: "# foo\n\n## bar\n",
+		},
+		{
+			desc:       "toc",
+			stdin:      "<!-- toc -->\n\n# foo\n\n## bar\n",
+			wantStdout: "<!-- toc -->\n\n- [foo](#foo)\n  - [bar](#bar)\n\n<!-- tocstop -->\n\n# foo\n\n## bar\n",
+		},
+		{
+			desc:       "reference-links",
+			args:       []string{"-reference-links", "numbered"},
+			stdin:      "[foo](https://example.com)",
+			wantStdout: "[foo][1]\n\n[1]: https://example.com\n",
+		},
+		{
+			desc:       "reference-links/unused",
+			args:       []string{"-reference-links", "numbered"},
+			stdin:      "[unused]: /u\n\n[foo](https://example.com)",
+			wantStdout: "[foo][1]\n\n[1]: https://example.com\n[unused]: /u\n",
+		},
+		{
+			desc:       "reference-links/width",
+			args:       []string{"-reference-links", "numbered", "-width", "10"},
+			stdin:      "see [foo](https://example.com) and bar baz",
+			wantStdout: "see\n[foo][1]\nand bar\nbaz\n\n[1]: https://example.com\n",
+		},
+		{
+			desc:       "bullet",
+			args:       []string{"-bullet", "-*"},
+			stdin:      "+ foo\n  + bar\n+ baz\n",
+			wantStdout: "- foo\n  * bar\n- baz\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -95,7 +160,13 @@ func TestParseArgs(t *testing.T) {
 		underlineHeadings bool
 		softWraps         bool
 		gofmt             bool
+		lineWidth         int
+		headingShift      int
 		listIndentStyle   markdown.ListIndentStyle
+		listNumbering     markdown.OrderedListNumbering
+		listDelimiter     rune
+		referenceLinks    markdown.ReferenceLinkMode
+		bulletMarkers     string
 	}
 
 	tests := []struct {
@@ -139,6 +210,21 @@ func TestParseArgs(t *testing.T) {
 			give: []string{"-gofmt"},
 			want: flags{gofmt: true},
 		},
+		{
+			desc: "width",
+			give: []string{"-width=80"},
+			want: flags{lineWidth: 80},
+		},
+		{
+			desc: "shift headings",
+			give: []string{"-shift-headings=2"},
+			want: flags{headingShift: 2},
+		},
+		{
+			desc: "shift headings/negative",
+			give: []string{"-shift-headings", "-1"},
+			want: flags{headingShift: -1},
+		},
 		{
 			desc: "list indent style/aligned",
 			give: []string{"-list-indent-style=aligned"},
@@ -149,6 +235,46 @@ func TestParseArgs(t *testing.T) {
 			give: []string{"-list-indent-style=uniform"},
 			want: flags{listIndentStyle: markdown.ListIndentUniform},
 		},
+		{
+			desc: "list numbering/sequential",
+			give: []string{"-list-numbering=sequential"},
+			want: flags{listNumbering: markdown.OrderedListSequential},
+		},
+		{
+			desc: "list numbering/same",
+			give: []string{"-list-numbering=same"},
+			want: flags{listNumbering: markdown.OrderedListSame},
+		},
+		{
+			desc: "list numbering/preserve",
+			give: []string{"-list-numbering", "preserve"},
+			want: flags{listNumbering: markdown.OrderedListPreserve},
+		},
+		{
+			desc: "list delimiter",
+			give: []string{"-list-delimiter=)"},
+			want: flags{listDelimiter: ')'},
+		},
+		{
+			desc: "reference links/numbered",
+			give: []string{"-reference-links=numbered"},
+			want: flags{referenceLinks: markdown.ReferenceLinksNumbered},
+		},
+		{
+			desc: "reference links/slug",
+			give: []string{"-reference-links", "slug"},
+			want: flags{referenceLinks: markdown.ReferenceLinksSlug},
+		},
+		{
+			desc: "bullet",
+			give: []string{"-bullet=*"},
+			want: flags{bulletMarkers: "*"},
+		},
+		{
+			desc: "bullet/rotation",
+			give: []string{"-bullet", "-*+"},
+			want: flags{bulletMarkers: "-*+"},
+		},
 		{
 			desc:     "file name with flags",
 			give:     []string{"-w", "foo.md", "bar/", "baz.md"},
@@ -184,7 +310,13 @@ func TestParseArgs(t *testing.T) {
 			assert.Equal(t, tt.want.underlineHeadings, cmd.underlineHeadings, "underlineHeadings")
 			assert.Equal(t, tt.want.softWraps, cmd.softWraps, "softWraps")
 			assert.Equal(t, tt.want.gofmt, cmd.gofmt, "gofmt")
+			assert.Equal(t, tt.want.lineWidth, cmd.lineWidth, "lineWidth")
+			assert.Equal(t, tt.want.headingShift, cmd.headingShift, "headingShift")
 			assert.Equal(t, tt.want.listIndentStyle, cmd.listIndentStyle, "listIndentStyle")
+			assert.Equal(t, tt.want.listNumbering, cmd.listNumbering, "listNumbering")
+			assert.Equal(t, tt.want.listDelimiter, cmd.listDelimiter, "listDelimiter")
+			assert.Equal(t, tt.want.referenceLinks, cmd.referenceLinks, "referenceLinks")
+			assert.Equal(t, tt.want.bulletMarkers, cmd.bulletMarkers, "bulletMarkers")
 			assert.Equal(t, tt.wantArgs, gotArgs, "args")
 		})
 	}
@@ -216,3 +348,59 @@ func TestParseArgs_UnknownListIndentStyle(t *testing.T) {
 	assert.Contains(t, stderr.String(), `invalid value "whatisthis"`)
 	assert.Contains(t, stderr.String(), `unrecognized style "whatisthis"`)
 }
+
+func TestParseArgs_UnknownListNumbering(t *testing.T) {
+	var stderr bytes.Buffer
+	cmd := mainCmd{
+		Stdin:  new(bytes.Buffer), // empty stdin
+		Stdout: io.Discard,
+		Stderr: &stderr,
+	}
+
+	_, err := cmd.parseArgs([]string{"-list-numbering=whatisthis"})
+	require.Error(t, err)
+	assert.Contains(t, stderr.String(), `invalid value "whatisthis"`)
+	assert.Contains(t, stderr.String(), `unrecognized numbering "whatisthis"`)
+}
+
+func TestParseArgs_UnknownListDelimiter(t *testing.T) {
+	var stderr bytes.Buffer
+	cmd := mainCmd{
+		Stdin:  new(bytes.Buffer), // empty stdin
+		Stdout: io.Discard,
+		Stderr: &stderr,
+	}
+
+	_, err := cmd.parseArgs([]string{"-list-delimiter=:"})
+	require.Error(t, err)
+	assert.Contains(t, stderr.String(), `invalid value ":"`)
+	assert.Contains(t, stderr.String(), `unrecognized delimiter ":"`)
+}
+
+func TestParseArgs_UnknownReferenceLinkMode(t *testing.T) {
+	var stderr bytes.Buffer
+	cmd := mainCmd{
+		Stdin:  new(bytes.Buffer), // empty stdin
+		Stdout: io.Discard,
+		Stderr: &stderr,
+	}
+
+	_, err := cmd.parseArgs([]string{"-reference-links=whatisthis"})
+	require.Error(t, err)
+	assert.Contains(t, stderr.String(), `invalid value "whatisthis"`)
+	assert.Contains(t, stderr.String(), `unrecognized mode "whatisthis"`)
+}
+
+func TestParseArgs_UnknownBulletMarker(t *testing.T) {
+	var stderr bytes.Buffer
+	cmd := mainCmd{
+		Stdin:  new(bytes.Buffer), // empty stdin
+		Stdout: io.Discard,
+		Stderr: &stderr,
+	}
+
+	_, err := cmd.parseArgs([]string{"-bullet=-o"})
+	require.Error(t, err)
+	assert.Contains(t, stderr.String(), `invalid value "-o"`)
+	assert.Contains(t, stderr.String(), `unrecognized marker 'o'`)
+}
@@ -0,0 +1,78 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestAutoLinks(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "bracketed",
+			give: "See <https://example.com> or <user@example.com>.\n",
+			want: "See <https://example.com> or <user@example.com>.\n",
+		},
+		{
+			desc: "extended",
+			give: "See https://example.com, www.example.com, or user@example.com.\n",
+			want: "See https://example.com, www.example.com, or user@example.com.\n",
+		},
+		{
+			desc: "line start",
+			give: joinLines(
+				"<https://example.com>",
+				"https://example.com",
+			),
+			want: joinLines(
+				"<https://example.com> https://example.com",
+			),
+		},
+		{
+			desc: "bracket extended",
+			opts: []Option{WithBracketedAutolinks()},
+			give: "See https://example.com, (www.example.com), or *user@example.com*.\n",
+			want: "See <https://example.com>, (<http://www.example.com>), or *<user@example.com>*.\n",
+		},
+		{
+			desc: "bracket extended/already bracketed",
+			opts: []Option{WithBracketedAutolinks()},
+			give: "See <https://example.com>.\n",
+			want: "See <https://example.com>.\n",
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.Linkify))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestIsBracketedAutoLink(t *testing.T) {
+	source := []byte("<https://example.com> https://example.com")
+
+	assert.True(t, isBracketedAutoLink(source, source[1:20]))
+	assert.False(t, isBracketedAutoLink(source, source[22:]))
+	assert.False(t, isBracketedAutoLink(source, source[:21]))
+	assert.False(t, isBracketedAutoLink(source, []byte("https://example.com")))
+}
@@ -0,0 +1,132 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestBulletMarker(t *testing.T) {
+	tests := []struct {
+		desc    string
+		markers []rune
+		give    string
+		want    string
+	}{
+		{
+			desc: "preserve",
+			give: joinLines(
+				"* foo",
+				"  + bar",
+			),
+			want: joinLines(
+				"* foo",
+				"  + bar",
+			),
+		},
+		{
+			desc:    "single",
+			markers: []rune{'-'},
+			give: joinLines(
+				"* foo",
+				"  + bar",
+				"* baz",
+			),
+			want: joinLines(
+				"- foo",
+				"  - bar",
+				"- baz",
+			),
+		},
+		{
+			desc:    "rotation",
+			markers: []rune{'-', '*', '+'},
+			give: joinLines(
+				"- foo",
+				"  - bar",
+				"    - baz",
+				"      - qux",
+			),
+			want: joinLines(
+				"- foo",
+				"  * bar",
+				"    + baz",
+				"      - qux",
+			),
+		},
+		{
+			desc:    "rotation/ordered list",
+			markers: []rune{'-', '*'},
+			give: joinLines(
+				"- foo",
+				"  1. bar",
+				"     - baz",
+			),
+			want: joinLines(
+				"- foo",
+				"  1. bar",
+				"     * baz",
+			),
+		},
+		{
+			desc:    "adjacent lists",
+			markers: []rune{'-'},
+			give: joinLines(
+				"- foo",
+				"",
+				"* bar",
+				"",
+				"+ baz",
+			),
+			want: joinLines(
+				"- foo",
+				"",
+				"* bar",
+				"",
+				"- baz",
+			),
+		},
+		{
+			desc:    "adjacent lists/rotation",
+			markers: []rune{'+', '*'},
+			give: joinLines(
+				"- foo",
+				"",
+				"* bar",
+			),
+			want: joinLines(
+				"+ foo",
+				"",
+				"* bar",
+			),
+		},
+		{
+			desc:    "invalid",
+			markers: []rune{'-', 'o'},
+			give: joinLines(
+				"* foo",
+			),
+			want: joinLines(
+				"* foo",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(WithBulletMarker(tt.markers...))
+
+			src := []byte(tt.give)
+			node := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
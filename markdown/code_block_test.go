@@ -0,0 +1,354 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestCodeFence(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "default",
+			give: joinLines(
+				"~~~go",
+				"foo()",
+				"~~~",
+			),
+			want: joinLines(
+				"```go",
+				"foo()",
+				"```",
+			),
+		},
+		{
+			desc: "nested fence",
+			give: joinLines(
+				"````markdown",
+				"```go",
+				"foo()",
+				"```",
+				"````",
+			),
+			want: joinLines(
+				"````markdown",
+				"```go",
+				"foo()",
+				"```",
+				"````",
+			),
+		},
+		{
+			desc: "longer nested fence",
+			give: joinLines(
+				"~~~markdown",
+				"  `````",
+				"~~~",
+			),
+			want: joinLines(
+				"``````markdown",
+				"  `````",
+				"``````",
+			),
+		},
+		{
+			desc: "not a closing fence",
+			give: joinLines(
+				"~~~markdown",
+				"```go",
+				"    ```",
+				"``` foo",
+				"~~~",
+			),
+			want: joinLines(
+				"```markdown",
+				"```go",
+				"    ```",
+				"``` foo",
+				"```",
+			),
+		},
+		{
+			desc: "backtick in info",
+			give: joinLines(
+				"~~~ a`b",
+				"foo",
+				"~~~",
+			),
+			want: joinLines(
+				"~~~a`b",
+				"foo",
+				"~~~",
+			),
+		},
+		{
+			desc: "indented code block",
+			give: joinLines(
+				"    ```",
+				"    foo",
+			),
+			want: joinLines(
+				"````",
+				"```",
+				"foo",
+				"````",
+			),
+		},
+		{
+			desc: "tildes",
+			opts: []Option{WithCodeFenceStyle('~', 3)},
+			give: joinLines(
+				"```go",
+				"foo()",
+				"~~~",
+				"```",
+			),
+			want: joinLines(
+				"~~~~go",
+				"foo()",
+				"~~~",
+				"~~~~",
+			),
+		},
+		{
+			desc: "minimum length",
+			opts: []Option{WithCodeFenceStyle('`', 5)},
+			give: joinLines(
+				"```go",
+				"foo()",
+				"```",
+			),
+			want: joinLines(
+				"`````go",
+				"foo()",
+				"`````",
+			),
+		},
+		{
+			desc: "invalid style",
+			opts: []Option{WithCodeFenceStyle('-', 2)},
+			give: joinLines(
+				"~~~~go",
+				"foo()",
+				"~~~~",
+			),
+			want: joinLines(
+				"```go",
+				"foo()",
+				"```",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestCodeBlockStyle(t *testing.T) {
+	tests := []struct {
+		desc  string
+		style CodeBlockStyle
+		opts  []Option
+		give  string
+		want  string
+	}{
+		{
+			desc:  "fenced",
+			style: CodeBlockFenced,
+			give: joinLines(
+				"    foo",
+				"      bar",
+			),
+			want: joinLines(
+				"```",
+				"foo",
+				"  bar",
+				"```",
+			),
+		},
+		{
+			desc:  "indented",
+			style: CodeBlockIndented,
+			give: joinLines(
+				"```",
+				"foo",
+				"",
+				"  bar",
+				"```",
+			),
+			want: joinLines(
+				"    foo",
+				"",
+				"      bar",
+			),
+		},
+		{
+			desc:  "indented/info string",
+			style: CodeBlockIndented,
+			give: joinLines(
+				"```go",
+				"foo()",
+				"```",
+			),
+			want: joinLines(
+				"```go",
+				"foo()",
+				"```",
+			),
+		},
+		{
+			desc:  "indented/leading blank line",
+			style: CodeBlockIndented,
+			give: joinLines(
+				"```",
+				"",
+				"foo",
+				"```",
+			),
+			want: joinLines(
+				"```",
+				"",
+				"foo",
+				"```",
+			),
+		},
+		{
+			desc:  "indented/empty",
+			style: CodeBlockIndented,
+			give: joinLines(
+				"```",
+				"```",
+			),
+			want: joinLines(
+				"```",
+				"```",
+			),
+		},
+		{
+			desc:  "indented/blockquote",
+			style: CodeBlockIndented,
+			give: joinLines(
+				"> foo",
+				">",
+				"> ```",
+				"> bar",
+				"> ```",
+			),
+			want: joinLines(
+				"> foo",
+				">",
+				">     bar",
+			),
+		},
+		{
+			desc:  "indented/list",
+			style: CodeBlockIndented,
+			give: joinLines(
+				"1. foo",
+				"",
+				"   ```",
+				"   bar",
+				"   ```",
+				"2. ```",
+				"   baz",
+				"   ```",
+			),
+			want: joinLines(
+				"1. foo",
+				"",
+				"       bar",
+				"",
+				"2. ```",
+				"   baz",
+				"   ```",
+			),
+		},
+		{
+			desc:  "indented/list/uniform",
+			style: CodeBlockIndented,
+			opts:  []Option{WithListIndentStyle(ListIndentUniform)},
+			give: joinLines(
+				"- foo",
+				"",
+				"  ```",
+				"  bar",
+				"  ```",
+			),
+			want: joinLines(
+				"- foo",
+				"",
+				"      bar",
+			),
+		},
+		{
+			desc:  "indented/after list",
+			style: CodeBlockIndented,
+			give: joinLines(
+				"- foo",
+				"",
+				"```",
+				"bar",
+				"```",
+			),
+			want: joinLines(
+				"- foo",
+				"",
+				"```",
+				"bar",
+				"```",
+			),
+		},
+		{
+			desc:  "preserve",
+			style: CodeBlockPreserve,
+			give: joinLines(
+				"    foo",
+				"",
+				"```",
+				"bar",
+				"```",
+			),
+			want: joinLines(
+				"    foo",
+				"",
+				"```",
+				"bar",
+				"```",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(append(tt.opts, WithCodeBlockStyle(tt.style))...)
+
+			src := []byte(tt.give)
+			node := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
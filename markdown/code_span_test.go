@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestCodeSpan(t *testing.T) {
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{desc: "plain", give: "`foo`", want: "`foo`"},
+		{desc: "backtick inside", give: "`` a ` b ``", want: "``a ` b``"},
+		{desc: "double backtick inside", give: "``` a `` b ```", want: "```a `` b```"},
+		{desc: "backtick at start", give: "`` `foo ``", want: "`` `foo ``"},
+		{desc: "backtick at end", give: "`` foo` ``", want: "`` foo` ``"},
+		{desc: "only backticks", give: "`` ` ``", want: "`` ` ``"},
+		{desc: "padded spaces", give: "`  foo  `", want: "`  foo  `"},
+		{desc: "leading space", give: "` foo`", want: "` foo`"},
+		{desc: "only spaces", give: "`   `", want: "`   `"},
+		{desc: "inner spaces", give: "`a   b`", want: "`a   b`"},
+		{desc: "multiple lines", give: "`foo\nbar\nbaz`", want: "`foo bar baz`"},
+		{desc: "table cell", give: "| a |\n|---|\n| `` `a\\|b` `` |", want: "| a            |\n|--------------|\n| `` `a\\|b` `` |"},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.Table))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, NewRenderer().Render(&buff, src, node))
+			assert.Equal(t, joinLines(tt.want), buff.String())
+		})
+	}
+}
+
+func TestLongestRun(t *testing.T) {
+	tests := []struct {
+		give string
+		want int
+	}{
+		{give: "", want: 0},
+		{give: "foo", want: 0},
+		{give: "`", want: 1},
+		{give: "a ` b `` c", want: 2},
+		{give: "```a`", want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			assert.Equal(t, tt.want, longestRun([]byte(tt.give), '`'))
+		})
+	}
+}
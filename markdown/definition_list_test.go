@@ -0,0 +1,138 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestDefinitionList(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "tight",
+			give: joinLines(
+				"Apple",
+				":   Pomaceous fruit.",
+				"",
+				"Orange",
+				"Citrus",
+				": The fruit of an *evergreen* tree.",
+			),
+			want: joinLines(
+				"Apple",
+				": Pomaceous fruit.",
+				"",
+				"Orange",
+				"Citrus",
+				": The fruit of an *evergreen* tree.",
+			),
+		},
+		{
+			desc: "loose",
+			give: joinLines(
+				"Apple",
+				"",
+				": Pomaceous fruit.",
+				"",
+				": A company.",
+			),
+			want: joinLines(
+				"Apple",
+				"",
+				": Pomaceous fruit.",
+				"",
+				": A company.",
+			),
+		},
+		{
+			desc: "multiple paragraphs",
+			give: joinLines(
+				"Term",
+				": First paragraph.",
+				"",
+				"    Second paragraph.",
+				"",
+				"    - item",
+			),
+			want: joinLines(
+				"Term",
+				": First paragraph.",
+				"",
+				"  Second paragraph.",
+				"",
+				"  - item",
+			),
+		},
+		{
+			desc: "uniform indent",
+			opts: []Option{WithListIndentStyle(ListIndentUniform)},
+			give: joinLines(
+				"Term",
+				": First paragraph.",
+				"",
+				"  Second paragraph.",
+			),
+			want: joinLines(
+				"Term",
+				": First paragraph.",
+				"",
+				"    Second paragraph.",
+			),
+		},
+		{
+			desc: "blockquote",
+			give: joinLines(
+				"Term",
+				": > Quoted",
+				"  > text.",
+			),
+			want: joinLines(
+				"Term",
+				": > Quoted text.",
+			),
+		},
+		{
+			desc: "surrounding blocks",
+			give: joinLines(
+				"Intro.",
+				"",
+				"Term",
+				": Definition.",
+				"# Heading",
+			),
+			want: joinLines(
+				"Intro.",
+				"",
+				"Term",
+				": Definition.",
+				"",
+				"# Heading",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.DefinitionList))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// escapeLineStart inserts a backslash escape into text written at the
+// start of a line, if needed, so that it isn't interpreted as the start
+// of a block: a heading, a list item, a block quote, a thematic break,
+// a setext heading underline, or a code fence.
+// wholeLine specifies whether nothing else follows the text on its line.
+//
+// Text that doesn't need escaping is returned unchanged.
+func escapeLineStart(text []byte, wholeLine bool) []byte {
+	line := text
+	if idx := bytes.IndexByte(line, '\n'); idx >= 0 {
+		line = line[:idx]
+	}
+	if len(line) == 0 {
+		return text
+	}
+
+	if idx := orderedListDelim(line); idx >= 0 {
+		return insertEscape(text, idx)
+	}
+
+	switch c := line[0]; c {
+	case '>':
+		return insertEscape(text, 0)
+	case '#':
+		n := countLeading(line, '#')
+		if n <= 6 && endsMarker(line[n:]) {
+			return insertEscape(text, 0)
+		}
+	case '+':
+		if endsMarker(line[1:]) {
+			return insertEscape(text, 0)
+		}
+	case '-', '*':
+		if endsMarker(line[1:]) || wholeLine && isBreakLine(line, c) {
+			return insertEscape(text, 0)
+		}
+	case '=', '_':
+		if wholeLine && isBreakLine(line, c) {
+			return insertEscape(text, 0)
+		}
+	case '`', '~':
+		if countLeading(line, c) >= 3 {
+			return insertEscape(text, 0)
+		}
+	}
+	return text
+}
+
+// orderedListDelim returns the index of the delimiter
+// if line starts with an ordered list marker like "1." or "1)",
+// and -1 otherwise.
+func orderedListDelim(line []byte) int {
+	n := 0
+	for n < len(line) && n < 10 && '0' <= line[n] && line[n] <= '9' {
+		n++
+	}
+	if n == 0 || n > 9 || n >= len(line) {
+		return -1
+	}
+	if (line[n] == '.' || line[n] == ')') && endsMarker(line[n+1:]) {
+		return n
+	}
+	return -1
+}
+
+// endsMarker reports whether the rest of a line following a block marker
+// makes it a marker: it's empty or starts with whitespace.
+func endsMarker(rest []byte) bool {
+	return len(rest) == 0 || rest[0] == ' ' || rest[0] == '\t'
+}
+
+// isBreakLine reports whether line consists only of the given character
+// and whitespace, with enough of the character to form a thematic break,
+// or a setext heading underline if c is '=' or '-'.
+func isBreakLine(line []byte, c byte) bool {
+	n := 0
+	for _, b := range line {
+		switch b {
+		case c:
+			n++
+		case ' ', '\t':
+		default:
+			return false
+		}
+	}
+	return n >= 3 || (n > 0 && (c == '=' || c == '-'))
+}
+
+// isThematicBreak reports whether line is a thematic break
+// without indentation or trailing whitespace.
+func isThematicBreak(line []byte) bool {
+	if len(line) == 0 || len(bytes.TrimSpace(line)) != len(line) {
+		return false
+	}
+	c := line[0]
+	return (c == '-' || c == '*' || c == '_') &&
+		isBreakLine(line, c) && bytes.Count(line, line[:1]) >= 3
+}
+
+func countLeading(line []byte, c byte) int {
+	n := 0
+	for n < len(line) && line[n] == c {
+		n++
+	}
+	return n
+}
+
+// insertEscape returns a copy of text with a backslash inserted at idx.
+func insertEscape(text []byte, idx int) []byte {
+	out := make([]byte, 0, len(text)+1)
+	out = append(out, text[:idx]...)
+	out = append(out, '\\')
+	return append(out, text[idx:]...)
+}
+
+// escapeUnescaped escapes occurrences of the given characters in text
+// that aren't already escaped with a backslash.
+func escapeUnescaped(text []byte, chars string) []byte {
+	return escapeFunc(text, func(text []byte, i int) bool {
+		return strings.IndexByte(chars, text[i]) >= 0
+	})
+}
+
+// escapeFunc escapes characters in text that aren't already escaped
+// with a backslash, and for which needsEscape reports true.
+func escapeFunc(text []byte, needsEscape func(text []byte, i int) bool) []byte {
+	var out []byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '\\' && i+1 < len(text) {
+			// Skip over existing escapes.
+			if out != nil {
+				out = append(out, c, text[i+1])
+			}
+			i++
+			continue
+		}
+
+		escape := needsEscape(text, i)
+		if escape && out == nil {
+			out = append(make([]byte, 0, len(text)+1), text[:i]...)
+		}
+		if out == nil {
+			continue
+		}
+		if escape {
+			out = append(out, '\\')
+		}
+		out = append(out, c)
+	}
+	if out == nil {
+		return text
+	}
+	return out
+}
+
+// inlineSpecialChars are characters that may have a special meaning
+// anywhere in inline Markdown.
+const inlineSpecialChars = "\\`*_[]<&~"
+
+// typographicPunctuation maps the HTML entities
+// that goldmark's typographer extension substitutes by default
+// to the punctuation they replace in the source.
+var typographicPunctuation = map[string][]byte{
+	"&lsquo;":  []byte("'"),
+	"&rsquo;":  []byte("'"),
+	"&ldquo;":  []byte(`"`),
+	"&rdquo;":  []byte(`"`),
+	"&ndash;":  []byte("--"),
+	"&mdash;":  []byte("---"),
+	"&hellip;": []byte("..."),
+	"&laquo;":  []byte("<<"),
+	"&raquo;":  []byte(">>"),
+}
+
+// escapeLiteral escapes text that holds literal characters,
+// so that none of them have a special meaning in Markdown.
+func escapeLiteral(text []byte) []byte {
+	var out []byte
+	for i, c := range text {
+		if bytes.IndexByte([]byte(inlineSpecialChars), c) >= 0 {
+			if out == nil {
+				out = append(make([]byte, 0, len(text)+1), text[:i]...)
+			}
+			out = append(out, '\\')
+		}
+		if out != nil {
+			out = append(out, c)
+		}
+	}
+	if out == nil {
+		return text
+	}
+	return out
+}
+
+// escapeClosingSequence escapes a trailing run of '#' in the contents of
+// an ATX heading so that it isn't taken for the optional closing sequence.
+//
+//	# Foo #   ->   # Foo \#
+func escapeClosingSequence(text []byte) []byte {
+	trimmed := bytes.TrimRight(text, "#")
+	if len(trimmed) == len(text) {
+		return text
+	}
+	if len(trimmed) > 0 && trimmed[len(trimmed)-1] != ' ' && trimmed[len(trimmed)-1] != '\t' {
+		// Part of a word, e.g. "C#".
+		return text
+	}
+	return insertEscape(text, len(trimmed))
+}
+
+// escapeText escapes text from the document,
+// given the context in which it's written,
+// so that it doesn't take on a different meaning in the output.
+// node is the inline node holding the text,
+// and raw specifies whether the text must be written as-is.
+func (r *render) escapeText(node ast.Node, text []byte, raw bool) []byte {
+	if raw {
+		return text
+	}
+
+	if r.emphasis > 0 {
+		// Emphasis tokens may differ from those in the source.
+		text = escapeEmphasisChars(text, r.emphToken[0], r.strongToken[0])
+	}
+	if r.tableCell {
+		text = escapeUnescaped(text, "|")
+	}
+	if r.w.AtLineStart() {
+		text = escapeLineStart(text, r.endsLine(node))
+	}
+	return text
+}
+
+// endsLine reports whether nothing follows the given inline node
+// on its line in the output.
+func (r *render) endsLine(node ast.Node) bool {
+	if tnode, ok := node.(*ast.Text); ok {
+		if tnode.HardLineBreak() {
+			return true
+		}
+		if tnode.SoftLineBreak() {
+			return r.mr.softWraps && !r.wrapping()
+		}
+	}
+	return node.NextSibling() == nil && node.Parent().Type() == ast.TypeBlock
+}
+
+// escapeEmphasisChars escapes unescaped occurrences of the given
+// emphasis characters in text,
+// except for underscores inside words, which can't delimit emphasis.
+func escapeEmphasisChars(text []byte, chars ...byte) []byte {
+	return escapeFunc(text, func(text []byte, i int) bool {
+		c := text[i]
+		if c == '_' && i > 0 && i+1 < len(text) && isWordChar(text[i-1]) && isWordChar(text[i+1]) {
+			return false
+		}
+		return bytes.IndexByte(chars, c) >= 0
+	})
+}
+
+func isWordChar(c byte) bool {
+	return c >= 0x80 || // non-ASCII
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
@@ -0,0 +1,231 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+func TestEscapeLineStart(t *testing.T) {
+	tests := []struct {
+		give      string
+		wholeLine bool
+		want      string
+	}{
+		{give: "foo", want: "foo"},
+		{give: "", want: ""},
+		{give: "# foo", want: `\# foo`},
+		{give: "###### foo", want: `\###### foo`},
+		{give: "####### foo", want: "####### foo"},
+		{give: "#hashtag", want: "#hashtag"},
+		{give: "#", want: `\#`},
+		{give: "- foo", want: `\- foo`},
+		{give: "+ foo", want: `\+ foo`},
+		{give: "* foo", want: `\* foo`},
+		{give: "-foo", want: "-foo"},
+		{give: "+++", wholeLine: true, want: "+++"},
+		{give: "> foo", want: `\> foo`},
+		{give: ">foo", want: `\>foo`},
+		{give: "1. foo", want: `1\. foo`},
+		{give: "123) foo", want: `123\) foo`},
+		{give: "1.5 foo", want: "1.5 foo"},
+		{give: "1234567890. foo", want: "1234567890. foo"},
+		{give: "---", wholeLine: true, want: `\---`},
+		{give: "---", wholeLine: false, want: "---"},
+		{give: "- - -", wholeLine: true, want: `\- - -`},
+		{give: "===", wholeLine: true, want: `\===`},
+		{give: "= foo", wholeLine: true, want: "= foo"},
+		{give: "___", wholeLine: true, want: `\___`},
+		{give: "```go", want: "\\```go"},
+		{give: "~~~", want: `\~~~`},
+		{give: "~~foo~~", want: "~~foo~~"},
+		{give: "foo\n# bar", want: "foo\n# bar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			got := escapeLineStart([]byte(tt.give), tt.wholeLine)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestEscapeClosingSequence(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "foo", want: "foo"},
+		{give: "foo #", want: `foo \#`},
+		{give: "foo ##", want: `foo \##`},
+		{give: "C#", want: "C#"},
+		{give: `foo \#`, want: `foo \#`},
+		{give: "#", want: `\#`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			got := escapeClosingSequence([]byte(tt.give))
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "escapes retained",
+			give: `\*not emphasis\* 1\. \# &lt;b&gt; &#42;`,
+			want: joinLines(`\*not emphasis\* 1\. \# &lt;b&gt; &#42;`),
+		},
+		{
+			desc: "line start retained",
+			give: joinLines(
+				`1\. not a list`,
+				"",
+				`\- not a list`,
+				"",
+				`\> not a quote`,
+			),
+			want: joinLines(
+				`1\. not a list`,
+				"",
+				`\- not a list`,
+				"",
+				`\> not a quote`,
+			),
+		},
+		{
+			desc: "setext headings",
+			opts: []Option{WithUnderlineHeadings()},
+			give: joinLines(
+				"# - item",
+				"",
+				"## 1. one",
+				"",
+				"# > quote",
+				"",
+				"# ---",
+			),
+			want: joinLines(
+				`\- item`,
+				"=======",
+				"",
+				`1\. one`,
+				"-------",
+				"",
+				`\> quote`,
+				"========",
+				"",
+				`\---`,
+				"====",
+			),
+		},
+		{
+			desc: "closing sequence",
+			give: joinLines(
+				"foo #",
+				"=====",
+				"",
+				"C#",
+				"--",
+			),
+			want: joinLines(
+				`# foo \#`,
+				"",
+				"## C#",
+			),
+		},
+		{
+			desc: "emphasis token",
+			give: "_a*b_ and __c*d__ and _snake_case_ and *e\\*f*",
+			want: joinLines(`*a\*b* and **c\*d** and *snake_case* and *e\*f*`),
+		},
+		{
+			desc: "table cells",
+			give: joinLines(
+				"| a | b |",
+				"|---|---|",
+				"| x \\| y | `a \\| b` |",
+			),
+			want: joinLines(
+				"| a      | b        |",
+				"|--------|----------|",
+				"| x \\| y | `a \\| b` |",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+// literalStrings replaces text with raw strings holding the same text
+// without backslash escapes.
+type literalStrings struct{}
+
+func (literalStrings) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var texts []*ast.Text
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if tnode, ok := node.(*ast.Text); ok && entering {
+			texts = append(texts, tnode)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, tnode := range texts {
+		value := util.UnescapePunctuations(tnode.Segment.Value(reader.Source()))
+		str := ast.NewString(value)
+		str.SetRaw(true)
+		tnode.Parent().ReplaceChild(tnode.Parent(), tnode, str)
+	}
+}
+
+func TestEscaping_Strings(t *testing.T) {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.Typographer),
+		goldmark.WithParserOptions(parser.WithASTTransformers(
+			util.Prioritized(literalStrings{}, 1000),
+		)),
+	)
+
+	src := []byte(joinLines(
+		`\*not emphasis\* -- [not a link] <x> a\\b`,
+		"",
+		`1\. not a list`,
+	))
+	node := md.Parser().Parse(text.NewReader(src))
+
+	var buff bytes.Buffer
+	require.NoError(t, NewRenderer().Render(&buff, src, node))
+	assert.Equal(t, joinLines(
+		`\*not emphasis\* -- \[not a link\] <x> a\\b`,
+		"",
+		`1\. not a list`,
+	), buff.String())
+}
@@ -0,0 +1,191 @@
+package markdown
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extAST "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// FootnoteDefinition is a block node that marks the position
+// at which a footnote was defined in the source.
+//
+//	[^label]: text
+//
+// Goldmark's footnote extension moves all footnotes into a
+// FootnoteList at the end of the document,
+// and drops footnotes that are never referenced.
+// Use the [FootnoteDefinitions] extension to leave these markers behind
+// so that the renderer can write footnotes back at their original position.
+type FootnoteDefinition struct {
+	ast.BaseBlock
+
+	// Footnote is the footnote defined at this position.
+	Footnote *extAST.Footnote
+}
+
+// Dump implements Node.Dump.
+func (n *FootnoteDefinition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Ref": string(n.Footnote.Ref),
+	}, nil)
+}
+
+// KindFootnoteDefinition is the NodeKind of the FootnoteDefinition node.
+var KindFootnoteDefinition = ast.NewNodeKind("FootnoteDefinition")
+
+// Kind implements Node.Kind.
+func (n *FootnoteDefinition) Kind() ast.NodeKind {
+	return KindFootnoteDefinition
+}
+
+// FootnoteDefinitions is a goldmark extension that enables
+// goldmark's footnote extension,
+// and retains the position of footnote definitions in the AST
+// as [FootnoteDefinition] nodes.
+//
+// Without this, footnote definitions are rendered at the end of the
+// document in the order in which they're first referenced.
+var FootnoteDefinitions goldmark.Extender = footnoteDefinitions{}
+
+type footnoteDefinitions struct{}
+
+func (footnoteDefinitions) Extend(m goldmark.Markdown) {
+	extension.Footnote.Extend(m)
+	m.Parser().AddOptions(parser.WithBlockParsers(
+		// Run before the footnote extension's parser,
+		// which is registered with priority 999.
+		util.Prioritized(footnoteBlockParser{extension.NewFootnoteBlockParser()}, 998),
+	))
+}
+
+// footnoteBlockParser wraps the footnote extension's block parser
+// to mark the position of footnotes before they're moved
+// into the footnote list.
+type footnoteBlockParser struct {
+	parser.BlockParser
+}
+
+func (p footnoteBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	if fn, ok := node.(*extAST.Footnote); ok {
+		def := &FootnoteDefinition{Footnote: fn}
+		def.SetBlankPreviousLines(node.HasBlankPreviousLines())
+		node.Parent().InsertBefore(node.Parent(), node, def)
+	}
+	p.BlockParser.Close(node, reader, pc)
+}
+
+// footnotes holds the footnotes of the document being rendered.
+type footnotes struct {
+	// byIndex maps footnote indexes to footnotes.
+	byIndex map[int]*extAST.Footnote
+
+	// placed is the set of footnotes with a FootnoteDefinition.
+	placed map[*extAST.Footnote]struct{}
+}
+
+func newFootnotes() *footnotes {
+	return &footnotes{
+		byIndex: make(map[int]*extAST.Footnote),
+		placed:  make(map[*extAST.Footnote]struct{}),
+	}
+}
+
+func (r *render) collectFootnotes(node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	switch tnode := node.(type) {
+	case *FootnoteDefinition:
+		r.footnotes.placed[tnode.Footnote] = struct{}{}
+	case *extAST.Footnote:
+		if tnode.Index >= 0 {
+			r.footnotes.byIndex[tnode.Index] = tnode
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+// rendersFootnote reports whether the given footnote
+// is rendered as part of the footnote list.
+func (r *render) rendersFootnote(node *extAST.Footnote) bool {
+	if r.mr.renumberFootnotes {
+		return true
+	}
+	_, placed := r.footnotes.placed[node]
+	return !placed
+}
+
+// footnoteLabel returns the label with which the footnote
+// with the given index and reference should be written.
+func (r *render) footnoteLabel(index int, ref []byte) []byte {
+	if r.mr.renumberFootnotes {
+		return strconv.AppendInt(nil, int64(index), 10)
+	}
+	return ref
+}
+
+func (r *render) renderFootnoteLink(node *extAST.FootnoteLink) {
+	var ref []byte
+	if fn, ok := r.footnotes.byIndex[node.Index]; ok {
+		ref = fn.Ref
+	}
+	_, _ = fmt.Fprintf(r.w, "[^%s]", r.footnoteLabel(node.Index, ref))
+}
+
+// renderFootnote renders the given footnote
+// with its content indented under the label.
+func (r *render) renderFootnote(node *extAST.Footnote) error {
+	marker := fmt.Sprintf("[^%s]:", r.footnoteLabel(node.Index, node.Ref))
+	_, _ = r.w.Write([]byte(marker))
+	if !node.HasChildren() {
+		return nil
+	}
+
+	_, _ = r.w.Write(spaceChar)
+	r.w.PushIndent(fourSpacesChars)
+	defer r.w.PopIndent()
+	// The first line starts after the marker, not the indentation.
+	r.markerOverhang = runewidth.StringWidth(marker) + 1 - len(fourSpacesChars)
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		err := ast.Walk(child, r.renderNode)
+		r.markerOverhang = 0
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFootnoteSeparator separates the given footnote-defining node
+// from the previous one.
+// Consecutive single-block footnotes are written on consecutive lines.
+func (r *render) writeFootnoteSeparator(node ast.Node) {
+	_, _ = r.w.Write(newLineChar)
+
+	prev := footnoteOf(r.previousSibling(node))
+	if prev == nil || prev.ChildCount() > 1 || footnoteOf(node).ChildCount() > 1 {
+		_, _ = r.w.Write(newLineChar)
+	}
+}
+
+// footnoteOf returns the footnote defined by the given node, if any.
+func footnoteOf(node ast.Node) *extAST.Footnote {
+	switch tnode := node.(type) {
+	case *extAST.Footnote:
+		return tnode
+	case *FootnoteDefinition:
+		return tnode.Footnote
+	default:
+		return nil
+	}
+}
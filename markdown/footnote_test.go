@@ -0,0 +1,184 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestFootnotes(t *testing.T) {
+	give := joinLines(
+		"Foo.[^note] Bar.[^1] Baz.[^note]",
+		"",
+		"[^1]: First.",
+		"[^note]: Second.",
+		"",
+		"    More about the second.",
+		"",
+		"[^unused]: Unused.",
+		"",
+		"> Quoted.[^q]",
+		">",
+		"> [^q]: In quote.",
+		"",
+		"Image-like ![^1] and [^missing].",
+	)
+
+	tests := []struct {
+		desc      string
+		extension goldmark.Extender
+		opts      []Option
+		want      string
+	}{
+		{
+			desc:      "in place",
+			extension: FootnoteDefinitions,
+			want: joinLines(
+				"Foo.[^note] Bar.[^1] Baz.[^note]",
+				"",
+				"[^1]: First.",
+				"",
+				"[^note]: Second.",
+				"",
+				"    More about the second.",
+				"",
+				"[^unused]: Unused.",
+				"",
+				"> Quoted.[^q]",
+				">",
+				"> [^q]: In quote.",
+				"",
+				"Image-like ![^1] and [^missing].",
+			),
+		},
+		{
+			desc:      "renumbered",
+			extension: FootnoteDefinitions,
+			opts:      []Option{WithRenumberedFootnotes()},
+			want: joinLines(
+				"Foo.[^1] Bar.[^2] Baz.[^1]",
+				"",
+				"> Quoted.[^3]",
+				"",
+				"Image-like ![^2] and [^missing].",
+				"",
+				"[^1]: Second.",
+				"",
+				"    More about the second.",
+				"",
+				"[^2]: First.",
+				"[^3]: In quote.",
+			),
+		},
+		{
+			// Without FootnoteDefinitions, the parser moves
+			// footnotes to the end of the document.
+			desc:      "no extension",
+			extension: extension.Footnote,
+			want: joinLines(
+				"Foo.[^note] Bar.[^1] Baz.[^note]",
+				"",
+				"> Quoted.[^q]",
+				"",
+				"Image-like ![^1] and [^missing].",
+				"",
+				"[^note]: Second.",
+				"",
+				"    More about the second.",
+				"",
+				"[^1]: First.",
+				"[^q]: In quote.",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithExtensions(tt.extension))
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestFootnotes_Blocks(t *testing.T) {
+	give := joinLines(
+		"Text.[^1]",
+		"",
+		"[^1]: - item",
+		"    - item",
+		"",
+		"    ```go",
+		"    code",
+		"    ```",
+		"[^2]:",
+	)
+	want := joinLines(
+		"Text.[^1]",
+		"",
+		"[^1]: - item",
+		"    - item",
+		"",
+		"    ```go",
+		"    code",
+		"    ```",
+		"",
+		"[^2]:",
+	)
+
+	md := goldmark.New(goldmark.WithExtensions(FootnoteDefinitions))
+	src := []byte(give)
+	node := md.Parser().Parse(text.NewReader(src))
+
+	var buff bytes.Buffer
+	require.NoError(t, NewRenderer().Render(&buff, src, node))
+	assert.Equal(t, want, buff.String())
+}
+
+func TestFootnotes_LineWidth(t *testing.T) {
+	give := joinLines(
+		"Text.[^1] More.[^long]",
+		"",
+		"[^1]: the note",
+		"",
+		"[^long]: a b c",
+		"",
+		"    d e f g h i",
+	)
+	want := joinLines(
+		"Text.[^1]",
+		"More.[^long]",
+		"",
+		"[^1]: the",
+		"    note",
+		"",
+		"[^long]: a b",
+		"    c",
+		"",
+		"    d e f g",
+		"    h i",
+	)
+
+	md := goldmark.New(goldmark.WithExtensions(FootnoteDefinitions))
+	src := []byte(give)
+	node := md.Parser().Parse(text.NewReader(src))
+
+	renderer := NewRenderer()
+	renderer.AddMarkdownOptions(WithLineWidth(12))
+
+	var buff bytes.Buffer
+	require.NoError(t, renderer.Render(&buff, src, node))
+	assert.Equal(t, want, buff.String())
+}
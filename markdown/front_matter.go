@@ -0,0 +1,228 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatterFormat specifies the format of a front matter block.
+type FrontMatterFormat int
+
+const (
+	// FrontMatterYAML is YAML front matter delimited by '---'.
+	//
+	//	---
+	//	title: Hello
+	//	---
+	FrontMatterYAML FrontMatterFormat = iota + 1
+
+	// FrontMatterTOML is TOML front matter delimited by '+++'.
+	//
+	//	+++
+	//	title = "Hello"
+	//	+++
+	FrontMatterTOML
+
+	// FrontMatterJSON is JSON front matter wrapped in '{' and '}'.
+	//
+	//	{
+	//	  "title": "Hello"
+	//	}
+	FrontMatterJSON
+)
+
+// FrontMatterBlock is a block node holding the front matter
+// at the start of a document.
+// Its lines include the opening and closing delimiters.
+type FrontMatterBlock struct {
+	ast.BaseBlock
+
+	// Format is the format of the front matter.
+	Format FrontMatterFormat
+}
+
+// IsRaw implements Node.IsRaw.
+func (n *FrontMatterBlock) IsRaw() bool {
+	return true
+}
+
+// Dump implements Node.Dump.
+func (n *FrontMatterBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// KindFrontMatterBlock is the NodeKind of the FrontMatterBlock node.
+var KindFrontMatterBlock = ast.NewNodeKind("FrontMatterBlock")
+
+// Kind implements Node.Kind.
+func (n *FrontMatterBlock) Kind() ast.NodeKind {
+	return KindFrontMatterBlock
+}
+
+// FrontMatter is a goldmark extension that parses YAML, TOML, and JSON
+// front matter at the start of a document into a [FrontMatterBlock].
+// Without this, front matter is parsed as regular Markdown,
+// e.g. as a thematic break followed by a setext heading.
+//
+// The front matter is rendered unchanged
+// unless [WithCanonicalYAMLFrontMatter] is used.
+var FrontMatter goldmark.Extender = frontMatter{}
+
+type frontMatter struct{}
+
+func (frontMatter) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(
+		// Run before the thematic break parser.
+		util.Prioritized(frontMatterParser{}, 0),
+	))
+}
+
+type frontMatterParser struct{}
+
+var _ parser.BlockParser = frontMatterParser{}
+
+var frontMatterDelims = map[FrontMatterFormat]struct{ open, close []string }{
+	FrontMatterYAML: {open: []string{"---"}, close: []string{"---", "..."}},
+	FrontMatterTOML: {open: []string{"+++"}, close: []string{"+++"}},
+	FrontMatterJSON: {open: []string{"{"}, close: []string{"}"}},
+}
+
+func (frontMatterParser) Trigger() []byte {
+	return []byte{'-', '+', '{'}
+}
+
+func (frontMatterParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	if parent.Kind() != ast.KindDocument || parent.HasChildren() || segment.Start != 0 {
+		return nil, parser.NoChildren
+	}
+
+	format := frontMatterFormat(line)
+	if format == 0 || !hasFrontMatterEnd(reader.Source()[segment.Stop:], format) {
+		// Without a closing delimiter, this is regular Markdown.
+		return nil, parser.NoChildren
+	}
+
+	node := &FrontMatterBlock{Format: format}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	return node, parser.NoChildren
+}
+
+func (frontMatterParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	if isFrontMatterDelim(line, frontMatterDelims[node.(*FrontMatterBlock).Format].close) {
+		return parser.Close
+	}
+	return parser.Continue | parser.NoChildren
+}
+
+func (frontMatterParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (frontMatterParser) CanInterruptParagraph() bool {
+	return false
+}
+
+func (frontMatterParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// frontMatterFormat returns the format of front matter opened by the
+// given line, or 0 if it doesn't open front matter.
+func frontMatterFormat(line []byte) FrontMatterFormat {
+	for format, delims := range frontMatterDelims {
+		if isFrontMatterDelim(line, delims.open) {
+			return format
+		}
+	}
+	return 0
+}
+
+// hasFrontMatterEnd reports whether the given source
+// has a line closing front matter of the given format.
+func hasFrontMatterEnd(source []byte, format FrontMatterFormat) bool {
+	for len(source) > 0 {
+		line := source
+		if idx := bytes.IndexByte(source, '\n'); idx >= 0 {
+			line, source = source[:idx+1], source[idx+1:]
+		} else {
+			source = nil
+		}
+
+		if isFrontMatterDelim(line, frontMatterDelims[format].close) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFrontMatterDelim reports whether the given line consists of
+// one of the given delimiters, ignoring trailing whitespace.
+func isFrontMatterDelim(line []byte, delims []string) bool {
+	line = util.TrimRightSpace(line)
+	for _, delim := range delims {
+		if string(line) == delim {
+			return true
+		}
+	}
+	return false
+}
+
+// formatYAML re-encodes the given YAML document with consistent
+// indentation, retaining the order of keys and comments.
+// In case of errors, it returns the document unchanged.
+func formatYAML(src []byte) []byte {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil || doc.Kind == 0 {
+		return src
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return src
+	}
+	if err := enc.Close(); err != nil {
+		return src
+	}
+	return buf.Bytes()
+}
+
+func (r *render) renderFrontMatter(node *FrontMatterBlock) {
+	lines := node.Lines()
+	opening, closing := lines.At(0), lines.At(lines.Len()-1)
+	if r.mr.formatFrontMatter && node.Format == FrontMatterYAML && lines.Len() > 2 {
+		var buf bytes.Buffer
+		for i := 1; i < lines.Len()-1; i++ {
+			line := lines.At(i)
+			_, _ = buf.Write(line.Value(r.source))
+		}
+
+		_, _ = r.w.Write(opening.Value(r.source))
+		_, _ = r.w.Write(formatYAML(buf.Bytes()))
+		_, _ = r.w.Write(bytes.TrimSuffix(closing.Value(r.source), newLineChar))
+		return
+	}
+
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		o := line.Value(r.source)
+		if i == lines.Len()-1 {
+			o = bytes.TrimSuffix(o, newLineChar)
+		}
+		_, _ = r.w.Write(o)
+	}
+}
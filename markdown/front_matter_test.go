@@ -0,0 +1,203 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestFrontMatter(t *testing.T) {
+	tests := []struct {
+		desc      string
+		canonical bool
+		give      string
+		want      string
+	}{
+		{
+			desc: "yaml",
+			give: joinLines(
+				"---",
+				"title:   Hello",
+				"tags:",
+				"    - a",
+				"---",
+				"Some text",
+				"----",
+			),
+			want: joinLines(
+				"---",
+				"title:   Hello",
+				"tags:",
+				"    - a",
+				"---",
+				"",
+				"## Some text",
+			),
+		},
+		{
+			desc: "yaml dots",
+			give: joinLines(
+				"---",
+				"title: Hello",
+				"...",
+				"",
+				"Text",
+			),
+			want: joinLines(
+				"---",
+				"title: Hello",
+				"...",
+				"",
+				"Text",
+			),
+		},
+		{
+			desc: "toml",
+			give: joinLines(
+				"+++",
+				`title = "Hello"`,
+				"",
+				"[params]",
+				"+++",
+				"",
+				"Text",
+			),
+			want: joinLines(
+				"+++",
+				`title = "Hello"`,
+				"",
+				"[params]",
+				"+++",
+				"",
+				"Text",
+			),
+		},
+		{
+			desc: "json",
+			give: joinLines(
+				"{",
+				`  "title": "Hello",`,
+				`  "params": {`,
+				`  }`,
+				"}",
+				"",
+				"Text",
+			),
+			want: joinLines(
+				"{",
+				`  "title": "Hello",`,
+				`  "params": {`,
+				`  }`,
+				"}",
+				"",
+				"Text",
+			),
+		},
+		{
+			desc: "unclosed",
+			give: joinLines(
+				"---",
+				"Text",
+			),
+			want: joinLines(
+				"---",
+				"",
+				"Text",
+			),
+		},
+		{
+			desc: "not at start",
+			give: joinLines(
+				"Text",
+				"",
+				"+++",
+				"foo",
+				"+++",
+			),
+			want: joinLines(
+				"Text",
+				"",
+				"+++ foo +++",
+			),
+		},
+		{
+			desc:      "canonical yaml",
+			canonical: true,
+			give: joinLines(
+				"---",
+				"title:   Hello",
+				"# comment",
+				"tags:",
+				"    - a",
+				"    - b",
+				"b: 1",
+				"a: 2",
+				"---",
+				"",
+				"Text",
+			),
+			want: joinLines(
+				"---",
+				"title: Hello",
+				"# comment",
+				"tags:",
+				"  - a",
+				"  - b",
+				"b: 1",
+				"a: 2",
+				"---",
+				"",
+				"Text",
+			),
+		},
+		{
+			desc:      "canonical invalid yaml",
+			canonical: true,
+			give: joinLines(
+				"---",
+				"title: [Hello",
+				"---",
+			),
+			want: joinLines(
+				"---",
+				"title: [Hello",
+				"---",
+			),
+		},
+		{
+			desc:      "canonical toml",
+			canonical: true,
+			give: joinLines(
+				"+++",
+				`title   =   "Hello"`,
+				"+++",
+			),
+			want: joinLines(
+				"+++",
+				`title   =   "Hello"`,
+				"+++",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(FrontMatter))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			if tt.canonical {
+				renderer.AddMarkdownOptions(WithCanonicalYAMLFrontMatter())
+			}
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestHardBreakStyle(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "spaces",
+			give: joinLines(
+				"foo\\",
+				"bar  ",
+				"baz",
+			),
+			want: joinLines(
+				"foo  ",
+				"bar  ",
+				"baz",
+			),
+		},
+		{
+			desc: "backslash",
+			opts: []Option{WithHardBreakStyle(HardBreakBackslash)},
+			give: joinLines(
+				"foo\\",
+				"bar  ",
+				"baz",
+			),
+			want: joinLines(
+				"foo\\",
+				"bar\\",
+				"baz",
+			),
+		},
+		{
+			desc: "backslash/escaped backslash",
+			opts: []Option{WithHardBreakStyle(HardBreakBackslash)},
+			give: joinLines(
+				"foo\\\\  ",
+				"bar",
+			),
+			want: joinLines(
+				"foo\\\\\\",
+				"bar",
+			),
+		},
+		{
+			desc: "backslash/after inline",
+			opts: []Option{WithHardBreakStyle(HardBreakBackslash)},
+			give: joinLines(
+				"*foo*  ",
+				"`bar`  ",
+				"baz",
+			),
+			want: joinLines(
+				"*foo*\\",
+				"`bar`\\",
+				"baz",
+			),
+		},
+		{
+			desc: "backslash/list",
+			opts: []Option{WithHardBreakStyle(HardBreakBackslash)},
+			give: joinLines(
+				"- foo  ",
+				"  bar",
+			),
+			want: joinLines(
+				"- foo\\",
+				"  bar",
+			),
+		},
+		{
+			desc: "soft wraps",
+			opts: []Option{WithSoftWraps()},
+			give: joinLines(
+				"foo\\",
+				"bar",
+				"baz",
+			),
+			want: joinLines(
+				"foo  ",
+				"bar",
+				"baz",
+			),
+		},
+		{
+			desc: "line width",
+			opts: []Option{WithLineWidth(10), WithHardBreakStyle(HardBreakBackslash)},
+			give: joinLines(
+				"foo bar baz qux\\",
+				"quux",
+			),
+			want: joinLines(
+				"foo bar",
+				"baz qux\\",
+				"quux",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package markdown
+
+import (
+	"bytes"
+	"strconv"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// HeadingIDs returns the IDs of all headings in the given document.
+//
+// Headings with an id attribute, like "# Foo {#bar}", keep that ID.
+// Other headings get a slug of their text the way GitHub builds anchors:
+// the text is lowercased, punctuation is dropped,
+// and spaces are replaced with '-'.
+// Duplicate slugs are suffixed with "-1", "-2", and so on.
+//
+//	# Hello, World!   ->   hello-world
+//	## Hello World    ->   hello-world-1
+//
+// Slugs may be empty if headings hold only punctuation.
+func HeadingIDs(source []byte, doc ast.Node) map[*ast.Heading]string {
+	return headingIDs(doc, SlugGitHub, func(heading *ast.Heading) []byte {
+		return headingText(source, heading, newLineChar)
+	})
+}
+
+// SlugStyle specifies how anchors of headings are built from their text.
+type SlugStyle int
+
+const (
+	// SlugGitHub builds anchors the way GitHub does,
+	// as described in [HeadingIDs].
+	//
+	//	# Foo -- Bar   ->   foo----bar
+	//
+	// This is the default.
+	SlugGitHub SlugStyle = iota
+
+	// SlugGitLab builds anchors the way GitLab does:
+	// like GitHub, but with runs of '-' collapsed into one.
+	//
+	//	# Foo -- Bar   ->   foo-bar
+	SlugGitLab
+)
+
+// headingIDs is like HeadingIDs,
+// with slugs built in the given style from the text returned by textOf.
+func headingIDs(doc ast.Node, style SlugStyle, textOf func(*ast.Heading) []byte) map[*ast.Heading]string {
+	slugs := newHeadingSlugs()
+	ids := make(map[*ast.Heading]string)
+
+	// Reserve IDs set by hand before generating slugs.
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if heading, ok := node.(*ast.Heading); ok && entering {
+			if id, ok := headingAttributeID(heading); ok {
+				slugs.Reserve(id)
+				ids[heading] = id
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		if _, ok := ids[heading]; !ok {
+			ids[heading] = slugs.Add(headingSlug(textOf(heading), style))
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	return ids
+}
+
+// headingAttributeID returns the value of the id attribute
+// of the given heading, if it has one.
+func headingAttributeID(node *ast.Heading) (string, bool) {
+	v, ok := node.AttributeString("id")
+	if !ok {
+		return "", false
+	}
+	switch id := v.(type) {
+	case []byte:
+		return string(id), true
+	case string:
+		return id, true
+	}
+	return "", false
+}
+
+// headingSlugs generates unique slugs for headings.
+type headingSlugs struct {
+	// Number of times each slug was generated,
+	// keyed by the slug before de-duplication.
+	seen map[string]int
+}
+
+func newHeadingSlugs() *headingSlugs {
+	return &headingSlugs{seen: make(map[string]int)}
+}
+
+// Reserve records that the given ID is in use.
+func (s *headingSlugs) Reserve(id string) {
+	if _, ok := s.seen[id]; !ok {
+		s.seen[id] = 0
+	}
+}
+
+// Add returns the given slug,
+// with a numeric suffix if it's already in use.
+func (s *headingSlugs) Add(slug string) string {
+	id := slug
+	for {
+		if _, taken := s.seen[id]; !taken {
+			break
+		}
+		s.seen[slug]++
+		id = slug + "-" + strconv.Itoa(s.seen[slug])
+	}
+	s.seen[id] = 0
+	return id
+}
+
+// headingSlug builds an anchor in the given style from the text of a heading.
+// Letters, digits, marks, '_', and '-' are kept,
+// spaces are replaced with '-', and everything else is dropped.
+func headingSlug(text []byte, style SlugStyle) string {
+	var slug []byte
+	for _, c := range string(bytes.ToLower(text)) {
+		switch {
+		case c == ' ' || c == '-':
+			if style == SlugGitLab && len(slug) > 0 && slug[len(slug)-1] == '-' {
+				continue
+			}
+			slug = append(slug, '-')
+		case c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) || unicode.IsMark(c):
+			slug = append(slug, string(c)...)
+		}
+	}
+	return string(slug)
+}
+
+// headingText returns the plain text of the given heading
+// as it would appear in HTML, without markup.
+// Soft line breaks are replaced with softBreak,
+// and hard line breaks with newlines.
+func headingText(source []byte, node ast.Node, softBreak []byte) []byte {
+	var text []byte
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		switch n := c.(type) {
+		case *ast.Text:
+			value := n.Segment.Value(source)
+			value = util.UnescapePunctuations(value)
+			value = util.ResolveNumericReferences(value)
+			value = util.ResolveEntityNames(value)
+			text = append(text, value...)
+			switch {
+			case n.HardLineBreak():
+				text = append(text, '\n')
+			case n.SoftLineBreak():
+				text = append(text, softBreak...)
+			}
+		case *ast.String:
+			text = append(text, n.Value...)
+		case *ast.CodeSpan:
+			text = append(text, codeSpanContent(source, n)...)
+		case *ast.AutoLink:
+			text = append(text, n.Label(source)...)
+		case *ast.RawHTML, *ast.Image:
+			// Neither has text in HTML.
+		default:
+			text = append(text, headingText(source, n, softBreak)...)
+		}
+	}
+	return text
+}
+
+// writtenHeadingIDs is like HeadingIDs, with slugs built in the given style
+// from the text of headings as they're written in the output.
+func (r *render) writtenHeadingIDs(doc ast.Node, style SlugStyle) map[*ast.Heading]string {
+	return headingIDs(doc, style, r.writtenHeadingText)
+}
+
+// writtenHeadingText returns the plain text of the given heading
+// as it's written in the output.
+// Only Setext headings keep line breaks.
+func (r *render) writtenHeadingText(heading *ast.Heading) []byte {
+	if !r.underlinesHeading(heading) {
+		return bytes.ReplaceAll(headingText(r.source, heading, spaceChar), newLineChar, spaceChar)
+	}
+	if r.mr.softWraps {
+		return headingText(r.source, heading, newLineChar)
+	}
+	return headingText(r.source, heading, spaceChar)
+}
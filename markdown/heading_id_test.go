@@ -0,0 +1,145 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestHeadingIDs(t *testing.T) {
+	src := []byte(joinLines(
+		"# Hello, World!",
+		"",
+		"## Hello World",
+		"",
+		"## Custom {#hello-world-2}",
+		"",
+		"## Hello *World*",
+		"",
+		"> ### `go test` &amp; friends",
+		"",
+		"- #### Ünïcödé_names [link](https://example.com) ![image](image.png)",
+		"",
+		"#### !!!",
+		"",
+		"Setext",
+		"heading",
+		"===",
+	))
+
+	md := goldmark.New(goldmark.WithParserOptions(parser.WithAttribute()))
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	var got []string
+	ids := HeadingIDs(src, doc)
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if heading, ok := node.(*ast.Heading); ok && entering {
+			got = append(got, ids[heading])
+		}
+		return ast.WalkContinue, nil
+	})
+
+	assert.Equal(t, []string{
+		"hello-world",
+		"hello-world-1",
+		"hello-world-2",
+		"hello-world-3",
+		"go-test--friends",
+		"ünïcödé_names-link-",
+		"",
+		"setextheading",
+	}, got)
+}
+
+func TestWithHeadingIDs(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "disabled",
+			give: "# Hello, World!\n",
+			want: "# Hello, World!\n",
+		},
+		{
+			desc: "atx",
+			opts: []Option{WithHeadingIDs()},
+			give: joinLines(
+				"# Hello, World!",
+				"",
+				"## Hello World",
+			),
+			want: joinLines(
+				"# Hello, World! {#hello-world}",
+				"",
+				"## Hello World {#hello-world-1}",
+			),
+		},
+		{
+			desc: "setext",
+			opts: []Option{WithHeadingIDs(), WithUnderlineHeadings()},
+			give: "# Hello, World!\n",
+			want: joinLines(
+				"Hello, World! {#hello-world}",
+				"============================",
+			),
+		},
+		{
+			desc: "existing attributes",
+			opts: []Option{WithHeadingIDs()},
+			give: joinLines(
+				"# Foo {#bar}",
+				"",
+				"# Bar {.baz}",
+			),
+			want: joinLines(
+				"# Foo {#bar}",
+				"",
+				"# Bar {#bar-1 .baz}",
+			),
+		},
+		{
+			desc: "empty slug",
+			opts: []Option{WithHeadingIDs()},
+			give: "# !!!\n",
+			want: "# !!!\n",
+		},
+		{
+			desc: "nested",
+			opts: []Option{WithHeadingIDs()},
+			give: joinLines(
+				"> # Quote",
+				"",
+				"- # Item",
+			),
+			want: joinLines(
+				"> # Quote {#quote}",
+				"",
+				"- # Item {#item}",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithParserOptions(parser.WithAttribute()))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestHeadingLevels(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "unchanged",
+			give: joinLines(
+				"# Foo",
+				"",
+				"### Bar",
+			),
+			want: joinLines(
+				"# Foo",
+				"",
+				"### Bar",
+			),
+		},
+		{
+			desc: "normalize/skipped levels",
+			opts: []Option{WithHeadingNormalization()},
+			give: joinLines(
+				"# Title",
+				"",
+				"### Foo",
+				"",
+				"###### Bar",
+				"",
+				"### Baz",
+				"",
+				"## Qux",
+			),
+			want: joinLines(
+				"# Title",
+				"",
+				"## Foo",
+				"",
+				"### Bar",
+				"",
+				"## Baz",
+				"",
+				"## Qux",
+			),
+		},
+		{
+			desc: "normalize/several titles",
+			opts: []Option{WithHeadingNormalization()},
+			give: joinLines(
+				"# Foo",
+				"",
+				"## Foo 1",
+				"",
+				"# Bar",
+				"",
+				"## Bar 1",
+			),
+			want: joinLines(
+				"# Foo",
+				"",
+				"## Foo 1",
+				"",
+				"## Bar",
+				"",
+				"### Bar 1",
+			),
+		},
+		{
+			desc: "normalize/no title",
+			opts: []Option{WithHeadingNormalization()},
+			give: joinLines(
+				"### Foo",
+				"",
+				"#### Bar",
+				"",
+				"# Baz",
+			),
+			want: joinLines(
+				"## Foo",
+				"",
+				"### Bar",
+				"",
+				"## Baz",
+			),
+		},
+		{
+			desc: "normalize/nested",
+			opts: []Option{WithHeadingNormalization()},
+			give: joinLines(
+				"# Foo",
+				"",
+				"> ### Bar",
+				"",
+				"- #### Baz",
+			),
+			want: joinLines(
+				"# Foo",
+				"",
+				"> ## Bar",
+				"",
+				"- ### Baz",
+			),
+		},
+		{
+			desc: "shift/demote",
+			opts: []Option{WithHeadingShift(1)},
+			give: joinLines(
+				"# Foo",
+				"",
+				"## Bar",
+				"",
+				"###### Baz",
+			),
+			want: joinLines(
+				"## Foo",
+				"",
+				"### Bar",
+				"",
+				"###### Baz",
+			),
+		},
+		{
+			desc: "shift/promote",
+			opts: []Option{WithHeadingShift(-2)},
+			give: joinLines(
+				"# Foo",
+				"",
+				"### Bar",
+			),
+			want: joinLines(
+				"# Foo",
+				"",
+				"# Bar",
+			),
+		},
+		{
+			desc: "shift after normalizing",
+			opts: []Option{WithHeadingNormalization(), WithHeadingShift(1)},
+			give: joinLines(
+				"# Foo",
+				"",
+				"### Bar",
+			),
+			want: joinLines(
+				"## Foo",
+				"",
+				"### Bar",
+			),
+		},
+		{
+			desc: "underline",
+			opts: []Option{WithUnderlineHeadings(), WithHeadingShift(1)},
+			give: joinLines(
+				"# Foo",
+				"",
+				"## Bar",
+			),
+			want: joinLines(
+				"Foo",
+				"---",
+				"",
+				"### Bar",
+			),
+		},
+	}
+
+	md := goldmark.New()
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
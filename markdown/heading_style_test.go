@@ -0,0 +1,249 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestHeadingStyle(t *testing.T) {
+	give := joinLines(
+		"# Foo",
+		"",
+		"Bar",
+		"---",
+		"",
+		"### Baz ###",
+	)
+
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "atx",
+			give: give,
+			want: joinLines(
+				"# Foo",
+				"",
+				"## Bar",
+				"",
+				"### Baz",
+			),
+		},
+		{
+			desc: "atx closed",
+			opts: []Option{WithHeadingStyle(HeadingATXClosed)},
+			give: give,
+			want: joinLines(
+				"# Foo #",
+				"",
+				"## Bar ##",
+				"",
+				"### Baz ###",
+			),
+		},
+		{
+			desc: "setext",
+			opts: []Option{WithHeadingStyle(HeadingSetext)},
+			give: give,
+			want: joinLines(
+				"Foo",
+				"===",
+				"",
+				"Bar",
+				"---",
+				"",
+				"### Baz",
+			),
+		},
+		{
+			desc: "preserve",
+			opts: []Option{WithHeadingStyle(HeadingPreserve)},
+			give: give,
+			want: joinLines(
+				"# Foo",
+				"",
+				"Bar",
+				"---",
+				"",
+				"### Baz ###",
+			),
+		},
+		{
+			desc: "preserve/shifted",
+			opts: []Option{WithHeadingStyle(HeadingPreserve), WithHeadingShift(1)},
+			give: give,
+			want: joinLines(
+				"## Foo",
+				"",
+				"### Bar",
+				"",
+				"#### Baz ####",
+			),
+		},
+		{
+			desc: "atx closed/trailing hashes",
+			opts: []Option{WithHeadingStyle(HeadingATXClosed)},
+			give: joinLines(
+				"# Foo \\#",
+				"",
+				"## C#",
+			),
+			want: joinLines(
+				"# Foo \\# #",
+				"",
+				"## C# ##",
+			),
+		},
+		{
+			desc: "atx closed/attributes",
+			opts: []Option{WithHeadingStyle(HeadingATXClosed)},
+			give: "## Foo {#bar}\n",
+			want: "## Foo ## {#bar}\n",
+		},
+		{
+			desc: "preserve/attributes",
+			opts: []Option{WithHeadingStyle(HeadingPreserve)},
+			give: joinLines(
+				"## Foo ## {#foo}",
+				"",
+				"## Bar {#bar}",
+			),
+			want: joinLines(
+				"## Foo ## {#foo}",
+				"",
+				"## Bar {#bar}",
+			),
+		},
+		{
+			desc: "atx/multi-line",
+			give: joinLines(
+				"Foo",
+				"bar",
+				"===",
+			),
+			want: "# Foo bar\n",
+		},
+		{
+			desc: "atx/multi-line/soft wraps",
+			opts: []Option{WithSoftWraps()},
+			give: joinLines(
+				"Foo",
+				"bar",
+				"===",
+			),
+			want: "# Foo bar\n",
+		},
+		{
+			desc: "atx/hard line break",
+			give: joinLines(
+				"Foo\\",
+				"bar",
+				"===",
+			),
+			want: joinLines(
+				"Foo  ",
+				"bar",
+				"===",
+			),
+		},
+		{
+			desc: "atx/hard line break/level 3",
+			opts: []Option{WithHeadingShift(2)},
+			give: joinLines(
+				"Foo\\",
+				"bar",
+				"===",
+			),
+			want: "### Foo bar\n",
+		},
+		{
+			desc: "setext/multi-line",
+			opts: []Option{WithHeadingStyle(HeadingSetext), WithSoftWraps()},
+			give: joinLines(
+				"Foo",
+				"bar baz",
+				"===",
+			),
+			want: joinLines(
+				"Foo",
+				"bar baz",
+				"=======",
+			),
+		},
+		{
+			desc: "setext/hard line break",
+			opts: []Option{WithHeadingStyle(HeadingSetext), WithHardBreakStyle(HardBreakBackslash)},
+			give: joinLines(
+				"Foo bar  ",
+				"baz",
+				"---",
+			),
+			want: joinLines(
+				"Foo bar\\",
+				"baz",
+				"-------",
+			),
+		},
+		{
+			desc: "setext/blockquote",
+			opts: []Option{WithHeadingStyle(HeadingSetext), WithSoftWraps()},
+			give: joinLines(
+				"> Foo",
+				"> bar",
+				"> ===",
+			),
+			want: joinLines(
+				"> Foo",
+				"> bar",
+				"> ===",
+			),
+		},
+		{
+			desc: "setext/list item",
+			opts: []Option{WithHeadingStyle(HeadingSetext), WithSoftWraps()},
+			give: joinLines(
+				"- # Foo",
+				"- Bar",
+				"  baz",
+				"  ---",
+			),
+			want: joinLines(
+				"- Foo",
+				"  ===",
+				"- Bar",
+				"  baz",
+				"  ---",
+			),
+		},
+		{
+			desc: "setext/empty",
+			opts: []Option{WithHeadingStyle(HeadingSetext)},
+			give: "#\n",
+			want: "#\n",
+		},
+	}
+
+	md := goldmark.New(goldmark.WithParserOptions(parser.WithAttribute()))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
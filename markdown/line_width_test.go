@@ -0,0 +1,185 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestLineWidth(t *testing.T) {
+	renderer := NewRenderer()
+	renderer.AddOptions(WithLineWidth(20))
+
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "short",
+			give: "foo bar\n",
+			want: "foo bar\n",
+		},
+		{
+			desc: "joins soft breaks",
+			give: joinLines(
+				"foo",
+				"bar",
+				"baz",
+			),
+			want: "foo bar baz\n",
+		},
+		{
+			desc: "wraps",
+			give: "the quick brown fox jumps over the lazy dog\n",
+			want: joinLines(
+				"the quick brown fox",
+				"jumps over the lazy",
+				"dog",
+			),
+		},
+		{
+			desc: "long word",
+			give: "a supercalifragilisticexpialidocious b\n",
+			want: joinLines(
+				"a",
+				"supercalifragilisticexpialidocious",
+				"b",
+			),
+		},
+		{
+			desc: "code span",
+			give: "some text `code span with spaces` here\n",
+			want: joinLines(
+				"some text",
+				"`code span with spaces`",
+				"here",
+			),
+		},
+		{
+			desc: "link",
+			give: "see [the link text](https://example.com) now\n",
+			want: joinLines(
+				"see",
+				"[the link text](https://example.com)",
+				"now",
+			),
+		},
+		{
+			desc: "image",
+			give: "see ![alt text here](img.png) now\n",
+			want: joinLines(
+				"see",
+				"![alt text here](img.png)",
+				"now",
+			),
+		},
+		{
+			desc: "emphasis",
+			give: "foo *emphasis across several words* bar\n",
+			want: joinLines(
+				"foo *emphasis across",
+				"several words* bar",
+			),
+		},
+		{
+			desc: "blockquote",
+			give: "> the quick brown fox jumps over the lazy dog\n",
+			want: joinLines(
+				"> the quick brown",
+				"> fox jumps over the",
+				"> lazy dog",
+			),
+		},
+		{
+			desc: "list item",
+			give: "- the quick brown fox jumps over the lazy dog\n",
+			want: joinLines(
+				"- the quick brown",
+				"  fox jumps over the",
+				"  lazy dog",
+			),
+		},
+		{
+			desc: "no new blocks",
+			give: "the quick brown fox - jumps over 1. the # lazy dog\n",
+			want: joinLines(
+				"the quick brown fox -",
+				"jumps over 1. the #",
+				"lazy dog",
+			),
+		},
+		{
+			desc: "hard line break",
+			give: joinLines(
+				"the quick brown fox\\",
+				"jumps",
+				"over the  ",
+				"lazy dog",
+			),
+			want: joinLines(
+				"the quick brown fox  ",
+				"jumps over the  ",
+				"lazy dog",
+			),
+		},
+		{
+			desc: "heading",
+			give: "# the quick brown fox jumps over the lazy dog\n",
+			want: "# the quick brown fox jumps over the lazy dog\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			src := []byte(tt.give)
+			node := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			got := buff.String()
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStartsBlock(t *testing.T) {
+	tests := []struct {
+		give string
+		want bool
+	}{
+		{"foo", false},
+		{"#", true},
+		{"######", true},
+		{"#######", false},
+		{"#foo", false},
+		{"-", true},
+		{"---", true},
+		{"===", true},
+		{"*", true},
+		{"+", true},
+		{"++", false},
+		{"__", false},
+		{"___", true},
+		{">foo", true},
+		{"<div>", true},
+		{"```go", true},
+		{"~~~", true},
+		{"|:-:|", true},
+		{"1.", true},
+		{"10)", true},
+		{"1.5", false},
+		{"1234567890.", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			assert.Equal(t, tt.want, startsBlock([]byte(tt.give)))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package markdown
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// LinkReferenceDefinition is a block node that retains a link reference
+// definition found in the source.
+//
+//	[label]: destination "title"
+//
+// Goldmark drops these definitions from the AST after resolving links.
+// Use the [LinkReferenceDefinitions] extension to keep them in the AST
+// so that the renderer can write them back at their original position.
+type LinkReferenceDefinition struct {
+	ast.BaseBlock
+
+	// Label is the raw label of the definition.
+	Label []byte
+
+	// Destination is the raw destination of the definition.
+	Destination []byte
+
+	// Title is the raw title of the definition, if any.
+	Title []byte
+}
+
+// Dump implements Node.Dump.
+func (n *LinkReferenceDefinition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Label":       string(n.Label),
+		"Destination": string(n.Destination),
+		"Title":       string(n.Title),
+	}, nil)
+}
+
+// KindLinkReferenceDefinition is the NodeKind of the
+// LinkReferenceDefinition node.
+var KindLinkReferenceDefinition = ast.NewNodeKind("LinkReferenceDefinition")
+
+// Kind implements Node.Kind.
+func (n *LinkReferenceDefinition) Kind() ast.NodeKind {
+	return KindLinkReferenceDefinition
+}
+
+// LinkReferenceDefinitions is a goldmark extension that retains
+// link reference definitions in the AST as [LinkReferenceDefinition] nodes.
+// Duplicate definitions are dropped,
+// matching how goldmark resolves links.
+//
+// This is required to render definitions at their original position
+// with [LinkReferencesInPlace].
+var LinkReferenceDefinitions goldmark.Extender = linkReferenceDefinitions{}
+
+type linkReferenceDefinitions struct{}
+
+func (linkReferenceDefinitions) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithParagraphTransformers(
+		// Run before parser.LinkReferenceParagraphTransformer
+		// so that definitions can be captured as they're extracted.
+		util.Prioritized(linkReferenceTransformer{}, 99),
+	))
+}
+
+type linkReferenceTransformer struct{}
+
+var _ parser.ParagraphTransformer = linkReferenceTransformer{}
+
+func (linkReferenceTransformer) Transform(node *ast.Paragraph, reader text.Reader, pc parser.Context) {
+	lines := node.Lines()
+	if lines.Len() == 0 {
+		return
+	}
+
+	known := make(map[string]struct{})
+	for _, ref := range pc.References() {
+		known[util.ToLinkReference(ref.Label())] = struct{}{}
+	}
+
+	// The transformer modifies lines in-place,
+	// so record the bounds of the paragraph ahead of time.
+	parent, next := node.Parent(), node.NextSibling()
+	start, stop := lines.At(0).Start, lines.At(lines.Len()-1).Stop
+	parser.LinkReferenceParagraphTransformer.Transform(node, reader, pc)
+
+	removed := node.Parent() == nil
+	if !removed {
+		stop = node.Lines().At(0).Start
+	}
+
+	// Definitions are extracted from the start of the paragraph.
+	// Find them in that region to retain their order.
+	region := reader.Source()[start:stop]
+	type definition struct {
+		ref parser.Reference
+		pos int
+	}
+	var defs []definition
+	for _, ref := range pc.References() {
+		if _, ok := known[util.ToLinkReference(ref.Label())]; ok {
+			continue
+		}
+
+		label := append(append([]byte{'['}, ref.Label()...), ']', ':')
+		defs = append(defs, definition{ref: ref, pos: bytes.Index(region, label)})
+	}
+	if len(defs) == 0 {
+		return
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].pos < defs[j].pos
+	})
+
+	// If the paragraph contained only definitions,
+	// it was replaced with an empty text block.
+	// Replace that with the definitions instead.
+	at := ast.Node(node)
+	if removed {
+		if next != nil {
+			at = next.PreviousSibling()
+		} else {
+			at = parent.LastChild()
+		}
+	}
+
+	for i, d := range defs {
+		def := &LinkReferenceDefinition{
+			Label:       d.ref.Label(),
+			Destination: d.ref.Destination(),
+			Title:       d.ref.Title(),
+		}
+		if i == 0 {
+			def.SetBlankPreviousLines(at.HasBlankPreviousLines())
+		}
+		parent.InsertBefore(parent, at, def)
+	}
+
+	if removed {
+		parent.RemoveChild(parent, at)
+	}
+}
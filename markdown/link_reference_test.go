@@ -0,0 +1,527 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestLinkReferenceStyle(t *testing.T) {
+	give := joinLines(
+		"[foo]: https://example.com/foo",
+		"[FOO]: https://example.com/duplicate",
+		"",
+		"See [foo], [the docs][docs], [Foo][], and ![logo][img].",
+		"Also [inline](https://example.com/inline).",
+		"",
+		"[docs]:",
+		"  https://example.com/docs",
+		"  'Documentation'",
+		"[img]: logo.png",
+		"[unused]: https://example.com/unused",
+	)
+
+	tests := []struct {
+		desc  string
+		style LinkReferenceStyle
+		want  string
+	}{
+		{
+			desc:  "inline",
+			style: LinkReferencesInline,
+			want: joinLines(
+				"See [foo](https://example.com/foo), [the docs](https://example.com/docs \"Documentation\"), [Foo](https://example.com/foo), and ![logo](logo.png). Also [inline](https://example.com/inline).",
+			),
+		},
+		{
+			desc:  "in place",
+			style: LinkReferencesInPlace,
+			want: joinLines(
+				"[foo]: https://example.com/foo",
+				"",
+				"See [foo], [the docs][docs], [Foo][], and ![logo][img]. Also [inline](https://example.com/inline).",
+				"",
+				"[docs]: https://example.com/docs \"Documentation\"",
+				"[img]: logo.png",
+				"[unused]: https://example.com/unused",
+			),
+		},
+		{
+			desc:  "at end",
+			style: LinkReferencesAtEnd,
+			want: joinLines(
+				"See [foo], [the docs][docs], [Foo][], and ![logo][img]. Also [inline](https://example.com/inline).",
+				"",
+				"[foo]: https://example.com/foo",
+				"[docs]: https://example.com/docs \"Documentation\"",
+				"[img]: logo.png",
+				"[unused]: https://example.com/unused",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(LinkReferenceDefinitions))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(WithLinkReferenceStyle(tt.style))
+
+			src := []byte(give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestLinkReferenceStyle_Links(t *testing.T) {
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "inline is unchanged",
+			give: "[foo](/url)\n",
+			want: "[foo](/url)\n",
+		},
+		{
+			desc: "inline image inside reference",
+			give: "[![badge](/badge.svg)][ci]\n\n[ci]: /ci\n",
+			want: "[![badge](/badge.svg)][ci]\n\n[ci]: /ci\n",
+		},
+		{
+			desc: "reference image inside inline link",
+			give: "[![badge][img]](/ci)\n\n[img]: /badge.svg\n",
+			want: "[![badge][img]](/ci)\n\n[img]: /badge.svg\n",
+		},
+		{
+			desc: "emphasis in text",
+			give: "[_foo_][bar]\n\n[bar]: /url\n",
+			want: "[*foo*][bar]\n\n[bar]: /url\n",
+		},
+		{
+			desc: "shortcut text changed",
+			give: "[_foo_]\n\n[_foo_]: /url\n",
+			want: "[*foo*][_foo_]\n\n[_foo_]: /url\n",
+		},
+		{
+			desc: "label whitespace",
+			give: "[foo][bar\n  baz]\n\n[bar   baz]: /url\n",
+			want: "[foo][bar baz]\n\n[bar baz]: /url\n",
+		},
+		{
+			desc: "missing definitions",
+			give: "[foo] and [foo][]\n\n[foo]: /url\n",
+			want: "[foo] and [foo][]\n\n[foo]: /url\n",
+		},
+		{
+			desc: "in list item",
+			give: "- [foo]: /url\n\n  [foo]\n",
+			want: "- [foo]: /url\n\n  [foo]\n",
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(LinkReferenceDefinitions))
+	renderer := NewRenderer()
+	renderer.AddMarkdownOptions(WithLinkReferenceStyle(LinkReferencesInPlace))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestLinkReferenceStyle_NoExtension(t *testing.T) {
+	// Without the extension, definitions are lost by the parser,
+	// so they must be added back at the end of the document.
+	renderer := NewRenderer()
+	renderer.AddMarkdownOptions(WithLinkReferenceStyle(LinkReferencesInPlace))
+
+	src := []byte(joinLines(
+		"[foo]: /foo",
+		"",
+		"See [foo] and [bar][Foo].",
+	))
+	node := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+	var buff bytes.Buffer
+	require.NoError(t, renderer.Render(&buff, src, node))
+	assert.Equal(t, joinLines(
+		"See [foo] and [bar][Foo].",
+		"",
+		"[foo]: /foo",
+	), buff.String())
+}
+
+func TestReferenceLinks(t *testing.T) {
+	tests := []struct {
+		desc  string
+		mode  ReferenceLinkMode
+		style LinkReferenceStyle
+		give  string
+		want  string
+	}{
+		{
+			desc: "numbered",
+			mode: ReferenceLinksNumbered,
+			give: "See [foo](/foo), [bar](/bar \"Bar\"), and ![baz](/baz.png).\n",
+			want: joinLines(
+				"See [foo][1], [bar][2], and ![baz][3].",
+				"",
+				"[1]: /foo",
+				`[2]: /bar "Bar"`,
+				"[3]: /baz.png",
+			),
+		},
+		{
+			desc: "slug",
+			mode: ReferenceLinksSlug,
+			give: "See [the *Go* docs](/go) and [Go docs!](/other).\n",
+			want: joinLines(
+				"See [the *Go* docs][the-go-docs] and [Go docs!][go-docs].",
+				"",
+				"[the-go-docs]: /go",
+				"[go-docs]: /other",
+			),
+		},
+		{
+			desc: "slug collision",
+			mode: ReferenceLinksSlug,
+			give: "[foo](/a), [foo](/b), and [foo](/a).\n",
+			want: joinLines(
+				"[foo][foo], [foo][foo-2], and [foo][foo].",
+				"",
+				"[foo]: /a",
+				"[foo-2]: /b",
+			),
+		},
+		{
+			desc: "slug without text",
+			mode: ReferenceLinksSlug,
+			give: "![](/img.png)\n",
+			want: joinLines(
+				"![][1]",
+				"",
+				"[1]: /img.png",
+			),
+		},
+		{
+			desc: "reuses urls",
+			mode: ReferenceLinksNumbered,
+			give: "[foo](/foo) and [bar](/foo) and [baz](/foo \"title\").\n",
+			want: joinLines(
+				"[foo][1] and [bar][1] and [baz][2].",
+				"",
+				"[1]: /foo",
+				`[2]: /foo "title"`,
+			),
+		},
+		{
+			desc: "existing labels",
+			mode: ReferenceLinksNumbered,
+			give: joinLines(
+				"[foo](/foo) and [bar][1] and [baz](/baz).",
+				"",
+				"[1]: /bar",
+				"[qux]: /baz",
+			),
+			want: joinLines(
+				"[foo][2] and [bar][1] and [baz][qux].",
+				"",
+				"[2]: /foo",
+				"[1]: /bar",
+				"[qux]: /baz",
+			),
+		},
+		{
+			desc:  "in place",
+			mode:  ReferenceLinksNumbered,
+			style: LinkReferencesInPlace,
+			give: joinLines(
+				"[foo](/foo) and [bar][1].",
+				"",
+				"[1]: /bar",
+				"",
+				"# Next",
+			),
+			want: joinLines(
+				"[foo][2] and [bar][1].",
+				"",
+				"[1]: /bar",
+				"",
+				"# Next",
+				"",
+				"[2]: /foo",
+			),
+		},
+		{
+			desc:  "sections",
+			mode:  ReferenceLinksNumbered,
+			style: LinkReferencesAtSectionEnd,
+			give: joinLines(
+				"# Intro",
+				"",
+				"See [foo](/foo).",
+				"",
+				"## Details",
+				"",
+				"- See [bar](/bar) and [foo](/foo).",
+				"",
+				"# Empty",
+			),
+			want: joinLines(
+				"# Intro",
+				"",
+				"See [foo][1].",
+				"",
+				"[1]: /foo",
+				"",
+				"## Details",
+				"",
+				"- See [bar][2] and [foo][1].",
+				"",
+				"[2]: /bar",
+				"",
+				"# Empty",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(LinkReferenceDefinitions))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(
+				WithReferenceLinks(tt.mode),
+				WithLinkReferenceStyle(tt.style),
+			)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestLinkReferenceStyle_Reformat(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "unreferenced",
+			opts: []Option{WithLinkReferenceStyle(LinkReferencesAtEnd)},
+			give: joinLines(
+				"[a][a]",
+				"",
+				"[u]: /u",
+				"[a]: /a",
+				"",
+				"[b][b]",
+				"",
+				"[b]: /b",
+			),
+			want: joinLines(
+				"[a][a]",
+				"",
+				"[b][b]",
+				"",
+				"[a]: /a",
+				"[b]: /b",
+				"[u]: /u",
+			),
+		},
+		{
+			desc: "label case",
+			opts: []Option{WithLinkReferenceStyle(LinkReferencesAtEnd)},
+			give: joinLines(
+				"[foo]: /url",
+				"",
+				"[Foo]",
+			),
+			want: joinLines(
+				"[Foo]",
+				"",
+				"[foo]: /url",
+			),
+		},
+		{
+			desc: "sections",
+			opts: []Option{WithLinkReferenceStyle(LinkReferencesAtSectionEnd)},
+			give: joinLines(
+				"# One",
+				"",
+				"[u]: /u",
+				"[b]: /b",
+				"",
+				"[a]",
+				"",
+				"# Two",
+				"",
+				"[b] and [a]",
+				"",
+				"[a]: /a",
+			),
+			want: joinLines(
+				"# One",
+				"",
+				"[a]",
+				"",
+				"[a]: /a",
+				"[u]: /u",
+				"[b]: /b",
+				"",
+				"# Two",
+				"",
+				"[b] and [a]",
+			),
+		},
+		{
+			desc: "numbered",
+			opts: []Option{WithReferenceLinks(ReferenceLinksNumbered)},
+			give: joinLines(
+				"[foo](/foo) and [bar][1].",
+				"",
+				"[unused]: /u",
+				"[1]: /bar",
+				"",
+				"[baz](/baz)",
+			),
+			want: joinLines(
+				"[foo][2] and [bar][1].",
+				"",
+				"[baz][3]",
+				"",
+				"[2]: /foo",
+				"[1]: /bar",
+				"[3]: /baz",
+				"[unused]: /u",
+			),
+		},
+		{
+			desc: "slug",
+			opts: []Option{WithReferenceLinks(ReferenceLinksSlug)},
+			give: joinLines(
+				"[unused]: /u",
+				"",
+				"[foo](/foo) and [bar](/bar).",
+			),
+			want: joinLines(
+				"[foo][foo] and [bar][bar].",
+				"",
+				"[foo]: /foo",
+				"[bar]: /bar",
+				"[unused]: /u",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(LinkReferenceDefinitions))
+	format := func(t *testing.T, opts []Option, src []byte) string {
+		renderer := NewRenderer()
+		renderer.AddMarkdownOptions(opts...)
+
+		var buff bytes.Buffer
+		require.NoError(t, renderer.Render(&buff, src, md.Parser().Parse(text.NewReader(src))))
+		return buff.String()
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := format(t, tt.opts, []byte(tt.give))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, got, format(t, tt.opts, []byte(got)), "second pass")
+		})
+	}
+}
+
+func TestLinkReferenceStyle_LineWidth(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "reference links",
+			opts: []Option{WithLinkReferenceStyle(LinkReferencesAtEnd)},
+			give: joinLines(
+				"See [the docs][docs] and then some more words that should wrap around nicely here ok.",
+				"",
+				"[docs]: https://example.com/docs",
+			),
+			want: joinLines(
+				"See [the docs][docs] and then",
+				"some more words that should",
+				"wrap around nicely here ok.",
+				"",
+				"[docs]: https://example.com/docs",
+			),
+		},
+		{
+			desc: "converted links",
+			opts: []Option{WithReferenceLinks(ReferenceLinksNumbered)},
+			give: "See [the docs](https://example.com/docs) and then some more words that should wrap around nicely here ok.\n",
+			want: joinLines(
+				"See [the docs][1] and then",
+				"some more words that should",
+				"wrap around nicely here ok.",
+				"",
+				"[1]: https://example.com/docs",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(LinkReferenceDefinitions))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(WithLineWidth(30))
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{"", ""},
+		{"foo", "foo"},
+		{"Foo Bar", "foo-bar"},
+		{"  *foo*  -- bar!", "foo-bar"},
+		{"Ünïcode 123", "ünïcode-123"},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(slugify([]byte(tt.give))))
+		})
+	}
+}
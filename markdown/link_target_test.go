@@ -0,0 +1,161 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestLinkTarget(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "plain",
+			give: `[foo](https://example.com "Example")` + "\n",
+			want: `[foo](https://example.com "Example")` + "\n",
+		},
+		{
+			desc: "balanced parentheses",
+			give: "[foo](foo(bar).md)\n",
+			want: "[foo](foo(bar).md)\n",
+		},
+		{
+			desc: "escaped parentheses",
+			give: `[foo](foo\(bar.md)` + "\n",
+			want: `[foo](foo\(bar.md)` + "\n",
+		},
+		{
+			desc: "spaces",
+			give: "[foo](<my file.md>) ![bar](<my image.png>)\n",
+			want: "[foo](<my file.md>) ![bar](<my image.png>)\n",
+		},
+		{
+			desc: "unbalanced parentheses",
+			give: "[foo](<foo(bar.md>)\n",
+			want: "[foo](<foo(bar.md>)\n",
+		},
+		{
+			desc: "empty",
+			give: `[foo](<> "Title")` + "\n",
+			want: `[foo](<> "Title")` + "\n",
+		},
+		{
+			desc: "angle brackets",
+			give: `[foo](<<foo\>>)` + "\n",
+			want: `[foo](<\<foo\>>)` + "\n",
+		},
+		{
+			desc: "title with double quotes",
+			give: `[foo](foo.md 'say "hi"')` + "\n",
+			want: `[foo](foo.md 'say "hi"')` + "\n",
+		},
+		{
+			desc: "title with all quotes",
+			give: `[foo](foo.md (it's "hi"))` + "\n",
+			want: `[foo](foo.md (it's "hi"))` + "\n",
+		},
+		{
+			desc: "title with all delimiters",
+			give: `[foo](foo.md "it's \"hi\" (1)")` + "\n",
+			want: `[foo](foo.md "it's \"hi\" (1)")` + "\n",
+		},
+		{
+			desc: "title with escaped delimiters",
+			give: `[foo](foo.md 'it\'s "hi" (1)')` + "\n",
+			want: `[foo](foo.md 'it\'s "hi" (1)')` + "\n",
+		},
+		{
+			desc: "title with unescaped delimiters",
+			give: `[foo](foo.md (it's "hi" (1)))` + "\n",
+			want: `[foo](foo.md "it's \"hi\" (1)")` + "\n",
+		},
+		{
+			desc: "single quotes",
+			opts: []Option{WithLinkTitleQuote('\'')},
+			give: `[foo](foo.md "Foo") [bar](bar.md "it's")` + "\n",
+			want: `[foo](foo.md 'Foo') [bar](bar.md "it's")` + "\n",
+		},
+		{
+			desc: "parentheses",
+			opts: []Option{WithLinkTitleQuote('(')},
+			give: `[foo](foo.md "Foo") [bar](bar.md "Bar (1)")` + "\n",
+			want: `[foo](foo.md (Foo)) [bar](bar.md "Bar (1)")` + "\n",
+		},
+		{
+			desc: "invalid quote",
+			opts: []Option{WithLinkTitleQuote('[')},
+			give: `[foo](foo.md 'Foo')` + "\n",
+			want: `[foo](foo.md "Foo")` + "\n",
+		},
+		{
+			desc: "reference definition",
+			opts: []Option{WithLinkReferenceStyle(LinkReferencesInPlace)},
+			give: joinLines(
+				"[foo]",
+				"",
+				`[foo]: <my file.md> 'say "hi"'`,
+			),
+			want: joinLines(
+				"[foo]",
+				"",
+				`[foo]: <my file.md> 'say "hi"'`,
+			),
+		},
+		{
+			desc: "reference definition/inlined",
+			give: joinLines(
+				"[foo]",
+				"",
+				`[foo]: <my file.md> 'say "hi"'`,
+			),
+			want: joinLines(
+				`[foo](<my file.md> 'say "hi"')`,
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(LinkReferenceDefinitions))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestNeedsAngleBrackets(t *testing.T) {
+	tests := []struct {
+		give string
+		want bool
+	}{
+		{"", true},
+		{"https://example.com", false},
+		{"foo(bar).md", false},
+		{`foo\(bar.md`, false},
+		{"foo(bar.md", true},
+		{"foo)bar.md", true},
+		{"my file.md", true},
+		{"foo\tbar", true},
+		{"<foo", true},
+		{`foo\`, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, needsAngleBrackets([]byte(tt.give)), "needsAngleBrackets(%q)", tt.give)
+	}
+}
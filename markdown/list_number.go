@@ -0,0 +1,97 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// listItemLinesKey is the parser context key under which
+// the first lines of ordered list items are collected.
+var listItemLinesKey = parser.NewContextKey()
+
+// ListItemNumbers is a goldmark extension that records
+// the number with which each item of an ordered list
+// was written in the source.
+// The line on which each ordered list item starts
+// is kept as the first of its Lines.
+//
+// Use this with [OrderedListPreserve] to retain these numbers.
+var ListItemNumbers goldmark.Extender = listItemNumbers{}
+
+type listItemNumbers struct{}
+
+func (listItemNumbers) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			// Run before goldmark's list item parser,
+			// which is registered with priority 400.
+			util.Prioritized(listItemNumberParser{parser.NewListItemParser()}, 399),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(listItemNumbers{}, 999),
+		),
+	)
+}
+
+// Transform sets the lines of ordered list items.
+// This happens after parsing inlines,
+// which would otherwise parse these lines into children of the items.
+func (listItemNumbers) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	lines, _ := pc.Get(listItemLinesKey).(map[*ast.ListItem]text.Segment)
+	for item, line := range lines {
+		item.Lines().Append(line)
+	}
+}
+
+// listItemNumberParser wraps goldmark's list item parser
+// to record the first lines of ordered list items.
+type listItemNumberParser struct {
+	parser.BlockParser
+}
+
+func (p listItemNumberParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	_, segment := reader.PeekLine()
+	node, state := p.BlockParser.Open(parent, reader, pc)
+	list, ok := parent.(*ast.List)
+	item, isItem := node.(*ast.ListItem)
+	if !ok || !isItem || !list.IsOrdered() {
+		return node, state
+	}
+
+	lines, _ := pc.Get(listItemLinesKey).(map[*ast.ListItem]text.Segment)
+	if lines == nil {
+		lines = make(map[*ast.ListItem]text.Segment)
+		pc.Set(listItemLinesKey, lines)
+	}
+	lines[item] = segment
+	return node, state
+}
+
+// sourceListItemNumber returns the number with which
+// the given ordered list item was written in the source,
+// if recorded by the ListItemNumbers extension.
+func sourceListItemNumber(source []byte, item *ast.ListItem) (int, bool) {
+	lines := item.Lines()
+	if lines.Len() == 0 {
+		return 0, false
+	}
+	line := lines.At(0)
+	return parseListItemNumber(line.Value(source))
+}
+
+// parseListItemNumber returns the number of the ordered list item
+// that starts on the given line.
+func parseListItemNumber(line []byte) (int, bool) {
+	line = util.TrimLeftSpace(line)
+	n, i := 0, 0
+	for ; i < len(line) && i < 10 && '0' <= line[i] && line[i] <= '9'; i++ {
+		n = n*10 + int(line[i]-'0')
+	}
+	if i == 0 || i > 9 || i >= len(line) || (line[i] != '.' && line[i] != ')') {
+		return 0, false
+	}
+	return n, true
+}
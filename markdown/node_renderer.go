@@ -0,0 +1,54 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark/ast"
+)
+
+// NodeRendererFunc renders nodes of a kind registered with
+// [WithNodeRenderer].
+//
+// Like an [ast.Walker], it's called when entering and leaving each node.
+// Returning [ast.WalkContinue] when entering renders the node's children
+// with the standard rendering.
+// Alternatively, render the children with [Writer.RenderChildren]
+// and return [ast.WalkSkipChildren].
+//
+// Block nodes are separated from their previous siblings
+// by a blank line before this is called.
+type NodeRendererFunc func(w *Writer, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error)
+
+// Writer writes the output of a [NodeRendererFunc].
+//
+// Lines written to the Writer are prefixed with the indentation
+// of the enclosing blocks, e.g. "> " inside block quotes.
+type Writer struct {
+	r *render
+}
+
+// Write writes the given bytes, indenting each new line.
+func (w *Writer) Write(b []byte) (int, error) {
+	return w.r.w.Write(b)
+}
+
+// PushIndent adds the given indentation to the start of all
+// following lines, after the indentation of the enclosing blocks.
+// Every call must be matched by a call to PopIndent.
+func (w *Writer) PushIndent(indent []byte) {
+	w.r.w.PushIndent(indent)
+}
+
+// PopIndent removes the indentation most recently added with PushIndent.
+func (w *Writer) PopIndent() {
+	w.r.w.PopIndent()
+}
+
+// RenderChildren renders the children of the given node
+// with the standard rendering.
+func (w *Writer) RenderChildren(node ast.Node) error {
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if err := ast.Walk(child, w.r.renderNode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// testAdmonition is a custom block node
+// that replaces block quotes in the tests below.
+type testAdmonition struct {
+	ast.BaseBlock
+}
+
+var kindTestAdmonition = ast.NewNodeKind("TestAdmonition")
+
+func (n *testAdmonition) Kind() ast.NodeKind { return kindTestAdmonition }
+
+func (n *testAdmonition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type testAdmonitionTransformer struct{}
+
+func (testAdmonitionTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var quotes []ast.Node
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && node.Kind() == ast.KindBlockquote {
+			quotes = append(quotes, node)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, quote := range quotes {
+		adm := &testAdmonition{}
+		for quote.HasChildren() {
+			adm.AppendChild(adm, quote.FirstChild())
+		}
+		quote.Parent().ReplaceChild(quote.Parent(), quote, adm)
+	}
+}
+
+func TestWithNodeRenderer(t *testing.T) {
+	renderAdmonition := func(w *Writer, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.Write([]byte("!!! note\n"))
+			w.PushIndent([]byte("    "))
+		} else {
+			w.PopIndent()
+		}
+		return ast.WalkContinue, nil
+	}
+
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "block",
+			opts: []Option{WithNodeRenderer(kindTestAdmonition, renderAdmonition)},
+			give: joinLines(
+				"Before.",
+				"",
+				"> Inside.",
+				">",
+				"> More.",
+				"",
+				"After.",
+			),
+			want: joinLines(
+				"Before.",
+				"",
+				"!!! note",
+				"    Inside.",
+				"",
+				"    More.",
+				"",
+				"After.",
+			),
+		},
+		{
+			desc: "nested",
+			opts: []Option{WithNodeRenderer(kindTestAdmonition, renderAdmonition)},
+			give: joinLines(
+				"- > Inside",
+				"  > a list.",
+			),
+			want: joinLines(
+				"- !!! note",
+				"      Inside a list.",
+			),
+		},
+		{
+			desc: "render children",
+			opts: []Option{
+				WithNodeRenderer(kindTestAdmonition, func(w *Writer, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+					if !entering {
+						return ast.WalkContinue, nil
+					}
+
+					_, _ = w.Write([]byte(":::\n"))
+					if err := w.RenderChildren(node); err != nil {
+						return ast.WalkStop, err
+					}
+					_, _ = w.Write([]byte("\n:::"))
+					return ast.WalkSkipChildren, nil
+				}),
+			},
+			give: joinLines(
+				"> Inside *quote*.",
+			),
+			want: joinLines(
+				":::",
+				"Inside *quote*.",
+				":::",
+			),
+		},
+		{
+			desc: "override inline",
+			opts: []Option{
+				WithNodeRenderer(ast.KindCodeSpan, func(w *Writer, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+					if entering {
+						_, _ = w.Write([]byte("<code>"))
+						_, _ = w.Write(node.Text(source))
+						_, _ = w.Write([]byte("</code>"))
+					}
+					return ast.WalkSkipChildren, nil
+				}),
+			},
+			give: "Use `foo` here.",
+			want: "Use <code>foo</code> here.\n",
+		},
+		{
+			desc: "unregistered",
+			give: "> Inside.",
+		},
+	}
+
+	md := goldmark.New(goldmark.WithParserOptions(
+		parser.WithASTTransformers(util.Prioritized(testAdmonitionTransformer{}, 0)),
+	))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			err := renderer.Render(&buff, src, node)
+			if tt.want == "" {
+				assert.ErrorContains(t, err, "unexpected tree type TestAdmonition")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestOrderedListNumbering(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "sequential",
+			give: joinLines(
+				"3. foo",
+				"3. bar",
+				"   1) baz",
+				"   1) qux",
+			),
+			want: joinLines(
+				"3. foo",
+				"4. bar",
+				"   1) baz",
+				"   2) qux",
+			),
+		},
+		{
+			desc: "same",
+			opts: []Option{WithOrderedListNumbering(OrderedListSame)},
+			give: joinLines(
+				"3. foo",
+				"4. bar",
+				"   1) baz",
+				"   2) qux",
+			),
+			want: joinLines(
+				"3. foo",
+				"3. bar",
+				"   1) baz",
+				"   1) qux",
+			),
+		},
+		{
+			desc: "preserve",
+			opts: []Option{WithOrderedListNumbering(OrderedListPreserve)},
+			give: joinLines(
+				"1. foo",
+				"1. bar",
+				"   1. baz",
+				"   3. qux",
+				"5. quux",
+			),
+			want: joinLines(
+				"1. foo",
+				"1. bar",
+				"   1. baz",
+				"   3. qux",
+				"5. quux",
+			),
+		},
+		{
+			desc: "delimiter",
+			opts: []Option{WithOrderedListDelimiter(')')},
+			give: joinLines(
+				"1. foo",
+				"2. bar",
+			),
+			want: joinLines(
+				"1) foo",
+				"2) bar",
+			),
+		},
+		{
+			desc: "delimiter/adjacent lists",
+			opts: []Option{WithOrderedListDelimiter('.')},
+			give: joinLines(
+				"1. foo",
+				"",
+				"1) bar",
+				"",
+				"1. baz",
+			),
+			want: joinLines(
+				"1. foo",
+				"",
+				"1) bar",
+				"",
+				"1. baz",
+			),
+		},
+		{
+			desc: "delimiter/invalid",
+			opts: []Option{WithOrderedListDelimiter(':')},
+			give: joinLines(
+				"1) foo",
+			),
+			want: joinLines(
+				"1) foo",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(ListItemNumbers))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestOrderedListNumbering_NoExtension(t *testing.T) {
+	renderer := NewRenderer()
+	renderer.AddMarkdownOptions(WithOrderedListNumbering(OrderedListPreserve))
+
+	src := []byte(joinLines(
+		"1. foo",
+		"1. bar",
+	))
+	node := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+	var buff bytes.Buffer
+	require.NoError(t, renderer.Render(&buff, src, node))
+	assert.Equal(t, joinLines(
+		"1. foo",
+		"2. bar",
+	), buff.String())
+}
+
+func TestListItemNumbers_Parse(t *testing.T) {
+	src := []byte(joinLines(
+		"1. foo",
+		"3. bar",
+		"",
+		"   5) baz",
+	))
+	md := goldmark.New(goldmark.WithExtensions(ListItemNumbers))
+	node := md.Parser().Parse(text.NewReader(src))
+
+	assert.Empty(t, node.(*ast.Document).Meta())
+	require.NoError(t, ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			assert.Empty(t, n.Attributes(), "%v node", n.Kind())
+		}
+		return ast.WalkContinue, nil
+	}))
+
+	// Other renderers are unaffected.
+	var want, got bytes.Buffer
+	require.NoError(t, goldmark.Convert(src, &want))
+	require.NoError(t, md.Convert(src, &got))
+	assert.Equal(t, want.String(), got.String())
+}
@@ -5,14 +5,14 @@ import (
 	"fmt"
 	"go/format"
 	"io"
-	"strconv"
+	"strings"
 	"unicode/utf8"
 	"unsafe"
 
+	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	extAST "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/renderer"
-	"github.com/yuin/goldmark/text"
 )
 
 var (
@@ -21,12 +21,15 @@ var (
 	strikeThroughChars      = []byte("~~")
 	thematicBreakChars      = []byte("---")
 	blockquoteChars         = []byte{'>', ' '}
-	codeBlockChars          = []byte("```")
+	hardBreakSpacesChars    = []byte("  ")
+	hardBreakBackslashChars = []byte{'\\'}
 	tableHeaderColChar      = []byte{'-'}
 	tableHeaderAlignColChar = []byte{':'}
 	heading1UnderlineChar   = []byte{'='}
 	heading2UnderlineChar   = []byte{'-'}
 	fourSpacesChars         = bytes.Repeat([]byte{' '}, 4)
+
+	definitionDescriptionChars = []byte{':', ' '}
 )
 
 // Ensure compatibility with Goldmark parser.
@@ -35,14 +38,38 @@ var _ renderer.Renderer = &Renderer{}
 // Renderer allows to render markdown AST into markdown bytes in consistent format.
 // Render is reusable across Renders, it holds configuration only.
 type Renderer struct {
-	underlineHeadings bool
+	headingStyle      HeadingStyle
 	softWraps         bool
+	lineWidth         int
 	emphToken         []byte
 	strongToken       []byte // if nil, use emphToken*2
 	listIndentStyle   ListIndentStyle
+	linkRefStyle      LinkReferenceStyle
+	refLinkMode       ReferenceLinkMode
+	formatFrontMatter bool
+	renumberFootnotes bool
+	codeFenceChar     byte
+	codeFenceLength   int
+	codeBlockStyle    CodeBlockStyle
+	hardBreakStyle    HardBreakStyle
+	bulletMarkers     []byte
+	listNumbering     OrderedListNumbering
+	listDelimiter     byte
+	tightLists        bool
+	thematicBreak     []byte
+	taskCheckChar     byte
+	bracketAutolinks  bool
+	linkTitleQuote    byte
+	headingIDs        bool
+	normalizeHeadings bool
+	headingShift      int
+	toc               TableOfContents
+	tableMaxWidth     int
 
 	// language name => format function
 	formatters map[string]func([]byte) []byte
+
+	nodeRenderers map[ast.NodeKind]NodeRendererFunc
 }
 
 // AddOptions pulls Markdown renderer specific options from the given list,
@@ -79,12 +106,369 @@ func (f optionFunc) apply(r *Renderer) {
 	f(r)
 }
 
+// GoldmarkOptions is an [Option] that holds options for the
+// goldmark.Markdown that the renderer is used with.
+// The renderer ignores these,
+// but markdownfmt.NewGoldmark and markdownfmt.Process apply them.
+//
+// This allows options that enable parser extensions
+// to be passed alongside other renderer options.
+type GoldmarkOptions []goldmark.Option
+
+var _ Option = GoldmarkOptions(nil)
+
+// SetConfig implements renderer.Option.
+func (GoldmarkOptions) SetConfig(*renderer.Config) {}
+
+func (GoldmarkOptions) apply(*Renderer) {}
+
+// WithCodeFenceStyle specifies the character and the minimum length
+// of the fences around fenced code blocks.
+// Per the CommonMark spec, valid characters are '`' and '~',
+// and fences are at least 3 characters long.
+// Invalid values are ignored.
+//
+//	~~~~ go
+//	fmt.Println("Hello")
+//	~~~~
+//
+// Longer fences are used for code that contains fences,
+// and tildes are used if the info string contains a backtick.
+//
+// Defaults to '`' and 3.
+func WithCodeFenceStyle(c rune, minLength int) Option {
+	return optionFunc(func(r *Renderer) {
+		if c == '`' || c == '~' {
+			r.codeFenceChar = byte(c)
+		}
+		if minLength >= 3 {
+			r.codeFenceLength = minLength
+		}
+	})
+}
+
+// CodeBlockStyle specifies how code blocks should be rendered.
+type CodeBlockStyle int
+
+const (
+	// CodeBlockFenced specifies that all code blocks
+	// should be rendered as fenced code blocks.
+	//
+	//	```
+	//	fmt.Println("Hello")
+	//	```
+	//
+	// This is the default.
+	CodeBlockFenced CodeBlockStyle = iota
+
+	// CodeBlockIndented specifies that code blocks without an info string
+	// should be rendered as indented code blocks.
+	//
+	//	    fmt.Println("Hello")
+	//
+	// Code blocks with an info string remain fenced.
+	CodeBlockIndented
+
+	// CodeBlockPreserve specifies that code blocks
+	// should retain the style they were written in.
+	CodeBlockPreserve
+)
+
+// WithCodeBlockStyle specifies how code blocks should be rendered.
+//
+// Code blocks are fenced regardless of the style
+// if they can't be written as indented code blocks:
+// if they're empty, begin or end with a blank line,
+// begin on the same line as a list item marker,
+// or follow a block that would take them in as a continuation.
+//
+// Defaults to [CodeBlockFenced].
+func WithCodeBlockStyle(style CodeBlockStyle) Option {
+	return optionFunc(func(r *Renderer) {
+		r.codeBlockStyle = style
+	})
+}
+
+// HardBreakStyle specifies how hard line breaks should be rendered.
+type HardBreakStyle int
+
+const (
+	// HardBreakSpaces specifies that hard line breaks
+	// should be written as two trailing spaces.
+	//
+	// This is the default.
+	HardBreakSpaces HardBreakStyle = iota
+
+	// HardBreakBackslash specifies that hard line breaks
+	// should be written as a trailing backslash.
+	// Unlike trailing spaces,
+	// these aren't lost to editors that strip trailing whitespace.
+	//
+	//	foo\
+	//	bar
+	HardBreakBackslash
+)
+
+// WithHardBreakStyle specifies how hard line breaks should be rendered,
+// regardless of how they were written in the source.
+//
+// Defaults to [HardBreakSpaces].
+func WithHardBreakStyle(style HardBreakStyle) Option {
+	return optionFunc(func(r *Renderer) {
+		r.hardBreakStyle = style
+	})
+}
+
+// WithBulletMarker specifies the markers of items in bullet lists.
+// Valid markers are '-', '*', and '+'.
+// Invalid values are ignored.
+//
+// With a single marker, all bullet lists use that marker.
+// With several markers, nested lists rotate through them by depth.
+//
+//	markdown.WithBulletMarker('-', '*', '+')
+//
+//	- foo
+//	  * bar
+//	    + baz
+//
+// Adjacent lists are kept apart by using a different marker,
+// since lists with the same marker would merge into one.
+//
+// By default, bullet lists retain the marker used in the source.
+func WithBulletMarker(markers ...rune) Option {
+	return optionFunc(func(r *Renderer) {
+		for _, m := range markers {
+			if !strings.ContainsRune(bulletListMarkers, m) {
+				return
+			}
+		}
+		r.bulletMarkers = []byte(string(markers))
+	})
+}
+
+// WithThematicBreak specifies the characters with which
+// thematic breaks should be written.
+// This must be a valid thematic break per the CommonMark spec:
+// 3 or more '-', '*', or '_' characters, optionally separated by spaces.
+// Invalid values are ignored.
+//
+//	markdown.WithThematicBreak("* * *")
+//
+// Thematic breaks are preceded by a blank line,
+// so "---" is never taken for a setext heading underline.
+// Inside tight list items, where that blank line would make the list loose,
+// breaks that follow a paragraph are written with '*' instead of '-'.
+//
+// Defaults to "---".
+func WithThematicBreak(s string) Option {
+	return optionFunc(func(r *Renderer) {
+		if isThematicBreak([]byte(s)) {
+			r.thematicBreak = []byte(s)
+		}
+	})
+}
+
+// WithTaskCheckStyle specifies the character
+// with which checked task list items should be written.
+// Valid characters are 'x' and 'X'.
+// Invalid values are ignored.
+//
+// By default, checked items retain the character used in the source.
+func WithTaskCheckStyle(c rune) Option {
+	return optionFunc(func(r *Renderer) {
+		if c == 'x' || c == 'X' {
+			r.taskCheckChar = byte(c)
+		}
+	})
+}
+
+// WithBracketedAutolinks wraps extended autolinks in angle brackets,
+// so that they're links in CommonMark renderers
+// that don't support GitHub Flavored Markdown.
+// Links without a scheme get one.
+//
+//	https://example.com   ->   <https://example.com>
+//	www.example.com       ->   <http://www.example.com>
+//
+// By default, autolinks are written with angle brackets
+// only if they were written with them in the source.
+func WithBracketedAutolinks() Option {
+	return optionFunc(func(r *Renderer) {
+		r.bracketAutolinks = true
+	})
+}
+
+// WithLinkTitleQuote specifies the delimiter with which
+// titles of links, images, and link reference definitions are written.
+// Valid delimiters are double quotes, single quotes, and '('
+// for titles wrapped in parentheses.
+// Invalid values are ignored.
+//
+//	markdown.WithLinkTitleQuote('\'')
+//
+//	[foo](https://example.com 'Example')
+//
+// Titles containing the preferred delimiter are written
+// with another one that they don't contain,
+// and escaped only if they contain all of them.
+//
+// Defaults to '"'.
+func WithLinkTitleQuote(c rune) Option {
+	return optionFunc(func(r *Renderer) {
+		if _, ok := linkTitleClosers[c]; ok {
+			r.linkTitleQuote = byte(c)
+		}
+	})
+}
+
+// WithHeadingIDs adds an id attribute to every heading that lacks one,
+// with a GitHub-style slug of its text as returned by [HeadingIDs].
+//
+//	# Hello, World!   ->   # Hello, World! {#hello-world}
+//
+// Attributes are only parsed back
+// if the parser is built with goldmark's parser.WithAttribute option,
+// as it is by markdownfmt.NewGoldmark.
+func WithHeadingIDs() Option {
+	return optionFunc(func(r *Renderer) {
+		r.headingIDs = true
+	})
+}
+
+// TableOfContents configures the tables of contents
+// generated between "<!-- toc -->" and "<!-- tocstop -->" markers.
+//
+//	<!-- toc -->
+//
+//	- [Installation](#installation)
+//	  - [Library](#library)
+//	- [Usage](#usage)
+//
+//	<!-- tocstop -->
+//
+// Tables of contents list the headings at the top level of the document,
+// and are rewritten every time the document is rendered.
+// If there's no "<!-- tocstop -->" marker after a "<!-- toc -->" marker,
+// one is added after the table of contents.
+type TableOfContents struct {
+	// MinDepth and MaxDepth are the lowest and highest levels
+	// of the headings that are listed,
+	// after normalization and shifting.
+	//
+	// Default to 1 and 6 if zero.
+	MinDepth, MaxDepth int
+
+	// Slugs specifies how the anchors of headings are built.
+	// Headings with an id attribute are linked to by that ID.
+	// With [WithHeadingIDs], headings are linked to by the IDs added to them,
+	// and this is ignored.
+	//
+	// Defaults to [SlugGitHub].
+	Slugs SlugStyle
+}
+
+// WithTableOfContents configures the tables of contents
+// generated between "<!-- toc -->" and "<!-- tocstop -->" markers.
+// See [TableOfContents] for details.
+//
+//	markdown.WithTableOfContents(markdown.TableOfContents{
+//		MinDepth: 2,
+//		MaxDepth: 3,
+//	})
+func WithTableOfContents(toc TableOfContents) Option {
+	return optionFunc(func(r *Renderer) {
+		r.toc = toc
+	})
+}
+
+// WithHeadingNormalization changes the levels of headings
+// so that there is at most one level 1 heading,
+// and no heading is more than one level below the one before it.
+//
+//	# Title          # Title
+//	### Foo     ->   ## Foo
+//	# Bar            ## Bar
+//	### Baz          ### Baz
+//
+// If the first heading of the document is at level 1,
+// it's kept as the title, and later level 1 headings are demoted below it.
+// Without one, the highest headings are written at level 2.
+func WithHeadingNormalization() Option {
+	return optionFunc(func(r *Renderer) {
+		r.normalizeHeadings = true
+	})
+}
+
+// WithHeadingShift adds n to the level of every heading,
+// demoting headings if n is positive and promoting them if it's negative.
+// Levels are kept between 1 and 6.
+// This is useful when embedding one document into another.
+//
+//	markdown.WithHeadingShift(1)
+//
+//	# Foo   ->   ## Foo
+//
+// If headings are normalized, they are shifted after normalization.
+func WithHeadingShift(n int) Option {
+	return optionFunc(func(r *Renderer) {
+		r.headingShift = n
+	})
+}
+
+// HeadingStyle specifies how headings should be rendered.
+type HeadingStyle int
+
+const (
+	// HeadingATX specifies that headings should be written
+	// with a leading sequence of '#' characters.
+	//
+	//	## Foo
+	//
+	// This is the default.
+	HeadingATX HeadingStyle = iota
+
+	// HeadingATXClosed specifies that headings should be written
+	// with both leading and closing sequences of '#' characters.
+	//
+	//	## Foo ##
+	HeadingATXClosed
+
+	// HeadingSetext specifies that headings of levels 1 and 2
+	// should be written as Setext headings,
+	// underlined with '=' and '-' respectively.
+	//
+	//	Foo
+	//	---
+	//
+	// Other headings are written as ATX headings.
+	HeadingSetext
+
+	// HeadingPreserve specifies that headings
+	// should retain the style they were written in.
+	// Setext headings that are moved below level 2,
+	// as by [WithHeadingShift], are written as ATX headings.
+	HeadingPreserve
+)
+
+// WithHeadingStyle specifies how headings should be rendered.
+//
+// Headings of levels 1 and 2 with hard line breaks
+// are written as Setext headings regardless of the style,
+// since ATX headings can't span lines.
+//
+// Defaults to [HeadingATX].
+func WithHeadingStyle(style HeadingStyle) Option {
+	return optionFunc(func(r *Renderer) {
+		r.headingStyle = style
+	})
+}
+
 // WithUnderlineHeadings configures the renderer to use
 // Setext-style headers (=== and ---).
+// This is the same as [WithHeadingStyle] with [HeadingSetext].
 func WithUnderlineHeadings() Option {
-	return optionFunc(func(r *Renderer) {
-		r.underlineHeadings = true
-	})
+	return WithHeadingStyle(HeadingSetext)
 }
 
 // WithSoftWraps allows you to wrap lines even on soft line breaks.
@@ -94,6 +478,46 @@ func WithSoftWraps() Option {
 	})
 }
 
+// WithLineWidth reflows paragraph text so that lines fit within
+// the given number of columns, measured the same way as table cells.
+// The width includes the indentation of enclosing blockquotes and list items.
+//
+// Code spans, links, and images are never split across lines,
+// so a line may exceed the width if a single word does not fit.
+// Reflowing takes precedence over [WithSoftWraps] inside paragraphs.
+//
+// Defaults to 0, which disables reflowing.
+func WithLineWidth(width int) Option {
+	return optionFunc(func(r *Renderer) {
+		r.lineWidth = width
+	})
+}
+
+// WithTableMaxWidth limits the padding of table columns
+// so that tables fit within the given number of columns where possible.
+// The width includes the indentation of enclosing blockquotes and list items.
+//
+// Columns are padded to the width of their widest cell as usual
+// if the table fits.
+// Otherwise, the widest columns are padded only up to a shared limit
+// chosen to fill the width, and cells wider than that are left unpadded,
+// so that the rest of the table stays aligned.
+// If even that doesn't fit, rows are written without padding.
+//
+//	markdown.WithTableMaxWidth(40)
+//
+//	| Name | Link                          |
+//	|------|-------------------------------|
+//	| foo  | https://example.com/foo/bar/baz |
+//	| bar  | https://example.com/bar       |
+//
+// Defaults to 0, which doesn't limit the width of tables.
+func WithTableMaxWidth(width int) Option {
+	return optionFunc(func(r *Renderer) {
+		r.tableMaxWidth = width
+	})
+}
+
 // WithEmphasisToken specifies the character used to wrap emphasised text.
 // Per the CommonMark spec, valid values are '*' and '_'.
 //
@@ -177,6 +601,232 @@ func WithListIndentStyle(style ListIndentStyle) Option {
 	})
 }
 
+// OrderedListNumbering specifies how items of ordered lists
+// should be numbered.
+type OrderedListNumbering int
+
+const (
+	// OrderedListSequential specifies that items should be numbered
+	// sequentially from the number of the first item.
+	//
+	//	1. foo
+	//	2. bar
+	//	3. baz
+	//
+	// This is the default.
+	OrderedListSequential OrderedListNumbering = iota
+
+	// OrderedListSame specifies that all items should use
+	// the number of the first item.
+	// This keeps diffs small when items are added or removed.
+	//
+	//	1. foo
+	//	1. bar
+	//	1. baz
+	OrderedListSame
+
+	// OrderedListPreserve specifies that items should retain
+	// the numbers with which they were written in the source.
+	//
+	// This requires the [ListItemNumbers] parser extension.
+	// Without it, items are numbered sequentially.
+	OrderedListPreserve
+)
+
+// WithOrderedListNumbering specifies how items of ordered lists
+// should be numbered.
+//
+// Defaults to [OrderedListSequential].
+func WithOrderedListNumbering(numbering OrderedListNumbering) Option {
+	return optionFunc(func(r *Renderer) {
+		r.listNumbering = numbering
+	})
+}
+
+// WithOrderedListDelimiter specifies the delimiter
+// that follows the numbers of ordered list items.
+// Valid delimiters are '.' and ')'.
+// Invalid values are ignored.
+//
+//	Steps:
+//
+//	1) foo
+//	2) bar
+//
+// Adjacent lists are kept apart by using a different delimiter,
+// since lists with the same delimiter would merge into one.
+//
+// By default, ordered lists retain the delimiter used in the source.
+func WithOrderedListDelimiter(delim rune) Option {
+	return optionFunc(func(r *Renderer) {
+		if delim == '.' || delim == ')' {
+			r.listDelimiter = byte(delim)
+		}
+	})
+}
+
+// WithTightLists renders lists without blank lines between items
+// if every item holds no more than a single paragraph,
+// even if the items are separated by blank lines in the source.
+// Other lists are rendered tight or loose as in the source.
+func WithTightLists() Option {
+	return optionFunc(func(r *Renderer) {
+		r.tightLists = true
+	})
+}
+
+// LinkReferenceStyle specifies how reference links
+// and link reference definitions should be rendered.
+type LinkReferenceStyle int
+
+const (
+	// LinkReferencesInline specifies that reference links
+	// should be rewritten into inline links,
+	// and link reference definitions should be dropped.
+	//
+	//	[foo][bar] => [foo](https://example.com)
+	//
+	// This is the default.
+	LinkReferencesInline LinkReferenceStyle = iota
+
+	// LinkReferencesInPlace specifies that reference links
+	// should be retained, and link reference definitions
+	// should be rendered where they appeared in the source.
+	//
+	// This requires the [LinkReferenceDefinitions] parser extension.
+	// Definitions that are referenced but missing from the AST
+	// are added to the end of the document.
+	LinkReferencesInPlace
+
+	// LinkReferencesAtEnd specifies that reference links
+	// should be retained, and link reference definitions
+	// should be collected at the end of the document
+	// in the order in which they are first referenced.
+	//
+	//	See [the docs][docs] and the [FAQ].
+	//
+	//	[docs]: https://example.com/docs
+	//	[FAQ]: https://example.com/faq
+	LinkReferencesAtEnd
+
+	// LinkReferencesAtSectionEnd specifies that reference links
+	// should be retained, and link reference definitions
+	// should be collected at the end of each top-level section:
+	// before every heading that is not nested inside another block,
+	// and at the end of the document.
+	LinkReferencesAtSectionEnd
+)
+
+// WithLinkReferenceStyle specifies how reference links
+// and link reference definitions should be rendered.
+// Definitions are normalized and de-duplicated
+// unless the style is [LinkReferencesInline].
+//
+// Defaults to [LinkReferencesInline].
+func WithLinkReferenceStyle(style LinkReferenceStyle) Option {
+	return optionFunc(func(r *Renderer) {
+		r.linkRefStyle = style
+	})
+}
+
+// ReferenceLinkMode specifies whether and how inline links and images
+// should be converted into reference links.
+type ReferenceLinkMode int
+
+const (
+	// ReferenceLinksNone specifies that inline links
+	// should be left as-is.
+	//
+	// This is the default.
+	ReferenceLinksNone ReferenceLinkMode = iota
+
+	// ReferenceLinksNumbered specifies that inline links
+	// should be converted into reference links
+	// labeled with sequential numbers.
+	//
+	//	See [the docs][1].
+	//
+	//	[1]: https://example.com/docs
+	ReferenceLinksNumbered
+
+	// ReferenceLinksSlug specifies that inline links
+	// should be converted into reference links
+	// labeled with a slug of the link text.
+	//
+	//	See [the docs][the-docs].
+	//
+	//	[the-docs]: https://example.com/docs
+	ReferenceLinksSlug
+)
+
+// WithReferenceLinks converts inline links and images into reference links
+// with the given labeling mode.
+// Links to the same destination and title share a single definition,
+// and labels never collide with those already in the document.
+//
+// Definitions are collected at the end of the document
+// unless [WithLinkReferenceStyle] specifies otherwise.
+//
+// Defaults to [ReferenceLinksNone].
+func WithReferenceLinks(mode ReferenceLinkMode) Option {
+	return optionFunc(func(r *Renderer) {
+		r.refLinkMode = mode
+	})
+}
+
+// WithCanonicalYAMLFrontMatter reformats YAML front matter parsed by the
+// [FrontMatter] extension with consistent indentation,
+// retaining the order of keys.
+// Front matter that is not valid YAML is left unchanged.
+//
+// By default, front matter is rendered exactly as written.
+func WithCanonicalYAMLFrontMatter() Option {
+	return optionFunc(func(r *Renderer) {
+		r.formatFrontMatter = true
+	})
+}
+
+// WithRenumberedFootnotes renumbers footnotes in the order in which
+// they're first referenced, and moves their definitions to the end of the
+// document in that order.
+// Footnotes that are never referenced are dropped.
+//
+//	Foo.[^note]     Foo.[^1]
+//	                 ->
+//	[^note]: Bar.   [^1]: Bar.
+//
+// By default, footnotes retain their labels,
+// and definitions retained by the [FootnoteDefinitions] extension
+// are rendered at their original position.
+func WithRenumberedFootnotes() Option {
+	return optionFunc(func(r *Renderer) {
+		r.renumberFootnotes = true
+	})
+}
+
+// WithNodeRenderer registers a function to render nodes of the given kind.
+// Use this to format documents that use custom goldmark extensions.
+// This takes precedence over the standard rendering of the kind, if any.
+//
+//	markdown.WithNodeRenderer(KindAdmonition,
+//		func(w *markdown.Writer, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+//			if entering {
+//				fmt.Fprintf(w, "!!! %s\n", node.(*Admonition).Title)
+//				w.PushIndent([]byte("    "))
+//			} else {
+//				w.PopIndent()
+//			}
+//			return ast.WalkContinue, nil
+//		})
+func WithNodeRenderer(kind ast.NodeKind, fn NodeRendererFunc) Option {
+	return optionFunc(func(r *Renderer) {
+		if r.nodeRenderers == nil {
+			r.nodeRenderers = make(map[ast.NodeKind]NodeRendererFunc)
+		}
+		r.nodeRenderers[kind] = fn
+	})
+}
+
 // CodeFormatter reformats code samples found in the document,
 // matching them by name.
 type CodeFormatter struct {
@@ -261,6 +911,11 @@ func NewRenderer() *Renderer {
 		// Leave strongToken as nil by default.
 		// At render time, we'll use what was specified,
 		// or repeat emphToken twice to get the strong token.
+
+		codeFenceChar:   '`',
+		codeFenceLength: 3,
+		thematicBreak:   thematicBreakChars,
+		linkTitleQuote:  '"',
 	}
 }
 
@@ -274,6 +929,54 @@ type render struct {
 	// TODO(bwplotka): Wrap it with something that catch errors.
 	w      *lineIndentWriter
 	source []byte
+
+	// wrap is non-nil while rendering the contents of a paragraph
+	// that will be reflowed. See renderWrapped.
+	wrap *wrapBuffer
+	// noBreak counts the enclosing spans that must not be split
+	// across lines when reflowing.
+	noBreak int
+	// markerOverhang is the width by which a block marker
+	// written before the next reflowed paragraph, like "[^1]: ",
+	// exceeds the indentation of the lines after it.
+	markerOverhang int
+
+	// tableCell is set while rendering the contents of a table cell.
+	tableCell bool
+	// singleLine is set while rendering contents
+	// that must be written on a single line, like those of ATX headings.
+	singleLine bool
+	// emphasis counts the enclosing emphasis nodes.
+	emphasis int
+
+	// Link reference definitions written or awaiting to be written.
+	// This is shared with inner renders.
+	refs *linkReferences
+
+	// Footnotes of the document.
+	// This is shared with inner renders.
+	footnotes *footnotes
+
+	// IDs of headings, if they're being added.
+	// This is shared with inner renders.
+	headingIDs map[*ast.Heading]string
+
+	// Normalized levels of headings, if they're being normalized.
+	// This is shared with inner renders.
+	headingLevels map[*ast.Heading]int
+
+	// Markers of tables of contents in the document, if any.
+	// This is shared with inner renders.
+	toc *tocMarkers
+}
+
+// hardBreakChars returns the characters that precede
+// the newline of a hard line break.
+func (mr *Renderer) hardBreakChars() []byte {
+	if mr.hardBreakStyle == HardBreakBackslash {
+		return hardBreakBackslashChars
+	}
+	return hardBreakSpacesChars
 }
 
 func (mr *Renderer) newRender(w io.Writer, source []byte) *render {
@@ -288,57 +991,184 @@ func (mr *Renderer) newRender(w io.Writer, source []byte) *render {
 		source:      source,
 		strongToken: strongToken,
 		emphToken:   mr.emphToken,
+		refs:        newLinkReferences(),
+		footnotes:   newFootnotes(),
 	}
 }
 
+// inner builds a render that writes to w,
+// sharing document-wide state with r.
+// Use this to render parts of the document separately.
+func (r *render) inner(w io.Writer) *render {
+	ir := r.mr.newRender(w, r.source)
+	ir.refs = r.refs
+	ir.footnotes = r.footnotes
+	ir.headingIDs = r.headingIDs
+	ir.headingLevels = r.headingLevels
+	ir.toc = r.toc
+	return ir
+}
+
+// innerSpan builds a render like inner
+// for content that is written after other text on the same line.
+func (r *render) innerSpan(w io.Writer) *render {
+	ir := r.inner(w)
+	ir.w.previousCharWasNewLine = false
+	return ir
+}
+
+// innerCell builds a render like inner for the contents of a table cell.
+func (r *render) innerCell(w io.Writer) *render {
+	ir := r.innerSpan(w)
+	ir.tableCell = true
+	return ir
+}
+
 // Render renders the given AST node to the given writer,
 // given the original source from which the node was parsed.
 //
 // NOTE: This is the entry point used by Goldmark.
 func (mr *Renderer) Render(w io.Writer, source []byte, node ast.Node) error {
+	r := mr.newRender(w, source)
+	if err := ast.Walk(node, r.collectFootnotes); err != nil {
+		return err
+	}
+	if mr.referenceStyle() != LinkReferencesInline {
+		// Reserve existing labels before new ones are generated.
+		if err := ast.Walk(node, r.collectLinkReferences); err != nil {
+			return err
+		}
+	}
+	if mr.headingIDs {
+		r.headingIDs = r.writtenHeadingIDs(node, SlugGitHub)
+	}
+	if mr.normalizeHeadings {
+		r.headingLevels = normalizeHeadingLevels(node)
+	}
+	r.toc = findTOCMarkers(source, node)
+
 	// Perform DFS.
-	return ast.Walk(node, mr.newRender(w, source).renderNode)
+	return ast.Walk(node, r.renderNode)
 }
 
 func (r *render) renderNode(node ast.Node, entering bool) (ast.WalkStatus, error) {
-	if entering && node.PreviousSibling() != nil {
+	if r.toc.Replaces(node) {
+		return ast.WalkSkipChildren, nil
+	}
+
+	if entering && r.mr.referenceStyle() == LinkReferencesAtSectionEnd &&
+		node.Kind() == ast.KindHeading && node.Parent().Kind() == ast.KindDocument &&
+		r.previousSibling(node) != nil {
+		r.writePendingLinkReferences(true)
+	}
+
+	if entering && r.renders(node) && r.previousSibling(node) != nil {
 		switch node.(type) {
 		// All Block types (except few) usually have 2x new lines before itself when they are non-first siblings.
 		case *ast.Paragraph, *ast.Heading, *ast.FencedCodeBlock,
-			*ast.CodeBlock, *ast.ThematicBreak, *extAST.Table,
-			*ast.Blockquote:
-			_, _ = r.w.Write(newLineChar)
+			*ast.CodeBlock, *extAST.Table, *ast.ThematicBreak,
+			*ast.Blockquote, *extAST.FootnoteList, *extAST.DefinitionList:
 			_, _ = r.w.Write(newLineChar)
+			if !r.separatesTightly(node) {
+				_, _ = r.w.Write(newLineChar)
+			}
 		case *ast.List, *ast.HTMLBlock:
 			_, _ = r.w.Write(newLineChar)
 			if node.HasBlankPreviousLines() {
 				_, _ = r.w.Write(newLineChar)
 			}
+		case *LinkReferenceDefinition:
+			_, _ = r.w.Write(newLineChar)
+			if r.previousSibling(node).Kind() != KindLinkReferenceDefinition {
+				_, _ = r.w.Write(newLineChar)
+			}
+		case *extAST.Footnote, *FootnoteDefinition:
+			r.writeFootnoteSeparator(node)
+		case *extAST.DefinitionTerm:
+			// Terms following a description start a new group.
+			_, _ = r.w.Write(newLineChar)
+			if r.previousSibling(node).Kind() == extAST.KindDefinitionDescription {
+				_, _ = r.w.Write(newLineChar)
+			}
+		case *extAST.DefinitionDescription:
+			_, _ = r.w.Write(newLineChar)
+			if !node.(*extAST.DefinitionDescription).IsTight {
+				_, _ = r.w.Write(newLineChar)
+			}
+		case *ast.TextBlock:
+			// Tight descriptions hold text blocks instead of paragraphs,
+			// but these must remain separate paragraphs.
+			switch node.Parent().Kind() {
+			case extAST.KindDefinitionDescription:
+				_, _ = r.w.Write(newLineChar)
+				_, _ = r.w.Write(newLineChar)
+			case ast.KindListItem:
+				// Items of tight lists hold text blocks too.
+				_, _ = r.w.Write(newLineChar)
+			}
 		case *ast.ListItem:
-			// TODO(bwplotka): Handle tight/loose rule explicitly.
+			// Items of loose lists are separated by blank lines.
 			// See: https://github.github.com/gfm/#loose
-			if node.HasBlankPreviousLines() {
+			if r.looseList(node.Parent().(*ast.List)) {
+				_, _ = r.w.Write(newLineChar)
+			}
+		default:
+			// Custom blocks are separated like paragraphs.
+			if _, ok := r.mr.nodeRenderers[node.Kind()]; ok && node.Type() == ast.TypeBlock {
+				_, _ = r.w.Write(newLineChar)
 				_, _ = r.w.Write(newLineChar)
 			}
 		}
 	}
 
+	if fn, ok := r.mr.nodeRenderers[node.Kind()]; ok {
+		return fn(&Writer{r: r}, r.source, node, entering)
+	}
+
 	switch tnode := node.(type) {
 	case *ast.Document:
 		if entering {
 			break
 		}
 
+		r.writePendingLinkReferences(r.hasOutput(tnode))
 		_, _ = r.w.Write(newLineChar)
 
 	// Spans, meaning no newlines before or after.
 	case *ast.Text:
 		if entering {
-			text := tnode.Segment.Value(r.source)
+			text := r.escapeText(tnode, tnode.Segment.Value(r.source), tnode.IsRaw())
+			if r.wrapping() {
+				r.writeWrapped(text)
+				break
+			}
 			_ = writeClean(r.w, text)
 			break
 		}
 
+		if r.singleLine {
+			if tnode.SoftLineBreak() {
+				_, _ = r.w.Write(spaceChar)
+			}
+			break
+		}
+
+		if tnode.HardLineBreak() {
+			_, _ = r.w.Write(r.mr.hardBreakChars())
+			_, _ = r.w.Write(newLineChar)
+			if r.wrapping() {
+				r.wrap.Break()
+			}
+			break
+		}
+
+		if r.wrapping() {
+			if tnode.SoftLineBreak() {
+				r.wrap.Break()
+			}
+			break
+		}
+
 		if tnode.SoftLineBreak() {
 			char := spaceChar
 			if r.mr.softWraps {
@@ -346,41 +1176,58 @@ func (r *render) renderNode(node ast.Node, entering bool) (ast.WalkStatus, error
 			}
 			_, _ = r.w.Write(char)
 		}
+	case *ast.String:
+		if !entering {
+			break
+		}
 
-		if tnode.HardLineBreak() {
-			if tnode.SoftLineBreak() {
-				_, _ = r.w.Write(spaceChar)
+		switch {
+		case tnode.IsCode():
+			if punct, ok := typographicPunctuation[string(tnode.Value)]; ok {
+				// Keep the punctuation replaced by goldmark's typographer.
+				_, _ = r.w.Write(punct)
+				break
 			}
-			_, _ = r.w.Write(newLineChar)
-		}
-	case *ast.String:
-		if entering {
+			// Code strings hold HTML.
 			_, _ = r.w.Write(tnode.Value)
+		case tnode.IsRaw():
+			_, _ = r.w.Write(r.escapeText(tnode, escapeLiteral(tnode.Value), false))
+		default:
+			_, _ = r.w.Write(r.escapeText(tnode, tnode.Value, false))
 		}
 	case *ast.AutoLink:
-		// We treat autolink as normal string.
 		if entering {
-			_, _ = r.w.Write(tnode.Label(r.source))
+			r.renderAutoLink(tnode)
 		}
 	case *extAST.TaskCheckBox:
 		if !entering {
 			break
 		}
-		if tnode.IsChecked {
-			_, _ = r.w.Write([]byte("[X] "))
-			break
+		r.renderTaskCheckBox(tnode)
+	case *extAST.FootnoteLink:
+		if entering {
+			r.renderFootnoteLink(tnode)
 		}
-		_, _ = r.w.Write([]byte("[ ] "))
+	case *extAST.FootnoteBacklink:
+		// Backlinks are generated for the footnote list.
+		break
 	case *ast.CodeSpan:
 		if entering {
-			_, _ = r.w.Write([]byte{'`'})
-			break
+			r.noBreak++
+			r.renderCodeSpan(tnode)
+			return ast.WalkSkipChildren, nil
 		}
 
-		_, _ = r.w.Write([]byte{'`'})
+		r.noBreak--
 	case *extAST.Strikethrough:
 		return r.wrapNonEmptyContentWith(strikeThroughChars, entering), nil
 	case *ast.Emphasis:
+		if entering {
+			r.emphasis++
+		} else {
+			r.emphasis--
+		}
+
 		var emWrapper []byte
 		switch tnode.Level {
 		case 1:
@@ -393,25 +1240,41 @@ func (r *render) renderNode(node ast.Node, entering bool) (ast.WalkStatus, error
 		return r.wrapNonEmptyContentWith(emWrapper, entering), nil
 	case *ast.Link:
 		if entering {
+			if ok, err := r.renderReferenceLink(tnode, tnode.Destination, tnode.Title); ok || err != nil {
+				return ast.WalkSkipChildren, err
+			}
+			r.noBreak++
 			r.w.AddIndentOnFirstWrite([]byte("["))
 			break
 		}
 
-		_, _ = fmt.Fprintf(r.w, "](%s", tnode.Destination)
-		if len(tnode.Title) > 0 {
-			_, _ = fmt.Fprintf(r.w, ` "%s"`, tnode.Title)
+		if _, ok := r.referenceLinkSource(tnode, tnode.Destination, tnode.Title); ok {
+			// Rendered in full on entering.
+			break
 		}
+		r.noBreak--
+
+		_, _ = r.w.Write([]byte("]("))
+		r.writeLinkTarget(tnode.Destination, tnode.Title)
 		_, _ = r.w.Write([]byte{')'})
 	case *ast.Image:
 		if entering {
+			if ok, err := r.renderReferenceLink(tnode, tnode.Destination, tnode.Title); ok || err != nil {
+				return ast.WalkSkipChildren, err
+			}
+			r.noBreak++
 			r.w.AddIndentOnFirstWrite([]byte("!["))
 			break
 		}
 
-		_, _ = fmt.Fprintf(r.w, "](%s", tnode.Destination)
-		if len(tnode.Title) > 0 {
-			_, _ = fmt.Fprintf(r.w, ` "%s"`, tnode.Title)
+		if _, ok := r.referenceLinkSource(tnode, tnode.Destination, tnode.Title); ok {
+			// Rendered in full on entering.
+			break
 		}
+		r.noBreak--
+
+		_, _ = r.w.Write([]byte("]("))
+		r.writeLinkTarget(tnode.Destination, tnode.Title)
 		_, _ = r.w.Write([]byte{')'})
 	case *ast.RawHTML:
 		if !entering {
@@ -425,7 +1288,17 @@ func (r *render) renderNode(node ast.Node, entering bool) (ast.WalkStatus, error
 		return ast.WalkSkipChildren, nil
 
 	// Blocks.
-	case *ast.Paragraph, *ast.TextBlock, *ast.List, *extAST.TableCell:
+	case *ast.Paragraph, *ast.TextBlock:
+		if !entering || r.mr.lineWidth <= 0 || r.wrap != nil {
+			break
+		}
+
+		if err := r.renderWrapped(node); err != nil {
+			return ast.WalkStop, fmt.Errorf("reflowing paragraph: %w", err)
+		}
+		return ast.WalkSkipChildren, nil
+	case *ast.List, *extAST.TableCell, *extAST.FootnoteList,
+		*extAST.DefinitionList, *extAST.DefinitionTerm:
 		// Things that has no content, just children elements, go there.
 		break
 	case *ast.Heading:
@@ -444,74 +1317,59 @@ func (r *render) renderNode(node ast.Node, entering bool) (ast.WalkStatus, error
 			break
 		}
 
-		var segments []text.Segment
-		for i := 0; i < node.Lines().Len(); i++ {
-			segments = append(segments, node.Lines().At(i))
+		_, _ = r.w.Write(htmlBlockContents(r.source, tnode))
+		if r.toc.Opens(tnode) {
+			r.renderTOC(tnode)
 		}
-
-		if tnode.ClosureLine.Len() != 0 {
-			segments = append(segments, tnode.ClosureLine)
-		}
-		for i, s := range segments {
-			o := s.Value(r.source)
-			if i == len(segments)-1 {
-				o = bytes.TrimSuffix(o, []byte("\n"))
-			}
-			_, _ = r.w.Write(o)
+		return ast.WalkSkipChildren, nil
+	case *FrontMatterBlock:
+		if !entering {
+			break
 		}
+
+		r.renderFrontMatter(tnode)
 		return ast.WalkSkipChildren, nil
 	case *ast.CodeBlock, *ast.FencedCodeBlock:
 		if !entering {
 			break
 		}
 
-		_, _ = r.w.Write(codeBlockChars)
-
-		var lang []byte
-		if fencedNode, isFenced := node.(*ast.FencedCodeBlock); isFenced && fencedNode.Info != nil {
-			lang = fencedNode.Info.Text(r.source)
-			_, _ = r.w.Write(lang)
-			for _, elt := range bytes.Fields(lang) {
-				elt = bytes.TrimSpace(bytes.TrimLeft(elt, ". "))
-				if len(elt) == 0 {
-					continue
-				}
-				lang = elt
-				break
-			}
+		r.renderCodeBlock(node)
+		return ast.WalkSkipChildren, nil
+	case *LinkReferenceDefinition:
+		if entering {
+			r.renderLinkReferenceDefinition(tnode)
 		}
-
-		_, _ = r.w.Write(newLineChar)
-		codeBuf := bytes.Buffer{}
-		for i := 0; i < tnode.Lines().Len(); i++ {
-			line := tnode.Lines().At(i)
-			_, _ = codeBuf.Write(line.Value(r.source))
+	case *extAST.Footnote:
+		if !entering {
+			break
 		}
 
-		if formatCode, ok := r.mr.formatters[noAllocString(lang)]; ok {
-			code := formatCode(codeBuf.Bytes())
-			if !bytes.HasSuffix(code, newLineChar) {
-				// Ensure code sample ends with a newline.
-				code = append(code, newLineChar...)
+		if r.rendersFootnote(tnode) {
+			if err := r.renderFootnote(tnode); err != nil {
+				return ast.WalkStop, fmt.Errorf("rendering footnote: %w", err)
 			}
-			_, _ = r.w.Write(code)
-		} else {
-			_, _ = r.w.Write(codeBuf.Bytes())
 		}
-
-		_, _ = r.w.Write(codeBlockChars)
 		return ast.WalkSkipChildren, nil
+	case *FootnoteDefinition:
+		if !entering || r.mr.renumberFootnotes {
+			break
+		}
+
+		if err := r.renderFootnote(tnode.Footnote); err != nil {
+			return ast.WalkStop, fmt.Errorf("rendering footnote: %w", err)
+		}
 	case *ast.ThematicBreak:
 		if !entering {
 			break
 		}
 
-		_, _ = r.w.Write(thematicBreakChars)
+		_, _ = r.w.Write(r.thematicBreak(tnode))
 	case *ast.Blockquote:
 		if entering {
 			r.w.PushIndent(blockquoteChars)
-			if node.Parent() != nil && node.Parent().Kind() == ast.KindListItem &&
-				node.PreviousSibling() == nil {
+			if node.Parent() != nil && startsOnMarkerLine(node.Parent()) &&
+				r.previousSibling(node) == nil {
 				_, _ = r.w.Write(blockquoteChars)
 			}
 		} else {
@@ -520,7 +1378,7 @@ func (r *render) renderNode(node ast.Node, entering bool) (ast.WalkStatus, error
 
 	case *ast.ListItem:
 		if entering {
-			liMarker := listItemMarkerChars(tnode)
+			liMarker := r.listItemMarkerChars(tnode)
 			_, _ = r.w.Write(liMarker)
 			if r.mr.listIndentStyle == ListIndentUniform &&
 				// We can use 4 spaces for indentation only if
@@ -545,6 +1403,18 @@ func (r *render) renderNode(node ast.Node, entering bool) (ast.WalkStatus, error
 			r.w.PopIndent()
 		}
 
+	case *extAST.DefinitionDescription:
+		if !entering {
+			r.w.PopIndent()
+			break
+		}
+
+		_, _ = r.w.Write(definitionDescriptionChars)
+		if r.mr.listIndentStyle == ListIndentUniform {
+			r.w.PushIndent(fourSpacesChars)
+		} else {
+			r.w.PushIndent(bytes.Repeat(spaceChar, len(definitionDescriptionChars)))
+		}
 	case *extAST.Table:
 		if !entering {
 			break
@@ -578,21 +1448,15 @@ func (r *render) wrapNonEmptyContentWith(b []byte, entering bool) ast.WalkStatus
 	return ast.WalkContinue
 }
 
-func listItemMarkerChars(tnode *ast.ListItem) []byte {
-	parList := tnode.Parent().(*ast.List)
-	if parList.IsOrdered() {
-		cnt := 1
-		if parList.Start != 0 {
-			cnt = parList.Start
-		}
-		s := tnode.PreviousSibling()
-		for s != nil {
-			cnt++
-			s = s.PreviousSibling()
-		}
-		return append(strconv.AppendInt(nil, int64(cnt), 10), parList.Marker, ' ')
+// startsOnMarkerLine reports whether the first child of the given node
+// is written on the same line as the node's marker.
+func startsOnMarkerLine(node ast.Node) bool {
+	switch node.Kind() {
+	case ast.KindListItem, extAST.KindDefinitionDescription, extAST.KindFootnote:
+		return true
+	default:
+		return false
 	}
-	return []byte{parList.Marker, spaceChar[0]}
 }
 
 func noAllocString(buf []byte) string {
@@ -0,0 +1,232 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	extAST "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// codeSpanContent returns the contents of the given code span
+// as parsed, with line endings replaced by spaces.
+func codeSpanContent(source []byte, node *ast.CodeSpan) []byte {
+	var content []byte
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		tnode, ok := c.(*ast.Text)
+		if !ok {
+			continue
+		}
+
+		value := tnode.Segment.Value(source)
+		if bytes.HasSuffix(value, newLineChar) {
+			value = value[:len(value)-1]
+			if c != node.LastChild() {
+				value = append(value[:len(value):len(value)], ' ')
+			}
+		}
+		content = append(content, value...)
+	}
+	return content
+}
+
+// renderCodeSpan writes the given code span with delimiters
+// long enough to contain any backticks inside it.
+func (r *render) renderCodeSpan(node *ast.CodeSpan) {
+	content := codeSpanContent(r.source, node)
+	if r.tableCell {
+		content = bytes.ReplaceAll(content, []byte{'|'}, []byte(`\|`))
+	}
+
+	delim := bytes.Repeat([]byte{'`'}, longestRun(content, '`')+1)
+
+	// A single space is stripped from both sides of the contents
+	// if they begin and end with one,
+	// and the contents can't begin or end with a backtick
+	// without being taken for part of the delimiter.
+	var pad []byte
+	if len(content) > 0 &&
+		(content[0] == '`' || content[len(content)-1] == '`' ||
+			content[0] == ' ' && content[len(content)-1] == ' ' && len(bytes.Trim(content, " ")) > 0) {
+		pad = spaceChar
+	}
+
+	_, _ = r.w.Write(delim)
+	_, _ = r.w.Write(pad)
+	_, _ = r.w.Write(content)
+	_, _ = r.w.Write(pad)
+	_, _ = r.w.Write(delim)
+}
+
+// longestRun returns the length of the longest run of c in b.
+func longestRun(b []byte, c byte) int {
+	var longest, n int
+	for _, x := range b {
+		if x != c {
+			n = 0
+			continue
+		}
+		n++
+		if n > longest {
+			longest = n
+		}
+	}
+	return longest
+}
+
+// renderCodeBlock writes the given indented or fenced code block
+// in the style specified by [WithCodeBlockStyle].
+func (r *render) renderCodeBlock(node ast.Node) {
+	info, lang, code := r.codeBlockContents(node)
+	if formatCode, ok := r.mr.formatters[noAllocString(lang)]; ok {
+		code = formatCode(code)
+		if !bytes.HasSuffix(code, newLineChar) {
+			// Ensure code sample ends with a newline.
+			code = append(code, newLineChar...)
+		}
+	}
+
+	if r.indentsCodeBlock(node, info, code) {
+		r.w.PushIndent(r.codeBlockIndent(node))
+		_, _ = r.w.Write(bytes.TrimSuffix(code, newLineChar))
+		r.w.PopIndent()
+		return
+	}
+
+	fence := r.mr.codeFence(info, code)
+	_, _ = r.w.Write(fence)
+	_, _ = r.w.Write(info)
+	_, _ = r.w.Write(newLineChar)
+	_, _ = r.w.Write(code)
+	_, _ = r.w.Write(fence)
+}
+
+// codeBlockContents returns the info string, the language,
+// and the unformatted contents of the given code block.
+func (r *render) codeBlockContents(node ast.Node) (info, lang, code []byte) {
+	if fencedNode, isFenced := node.(*ast.FencedCodeBlock); isFenced && fencedNode.Info != nil {
+		info = fencedNode.Info.Text(r.source)
+		lang = info
+		for _, elt := range bytes.Fields(info) {
+			elt = bytes.TrimSpace(bytes.TrimLeft(elt, ". "))
+			if len(elt) == 0 {
+				continue
+			}
+			lang = elt
+			break
+		}
+	}
+
+	var codeBuf bytes.Buffer
+	for i := 0; i < node.Lines().Len(); i++ {
+		line := node.Lines().At(i)
+		_, _ = codeBuf.Write(line.Value(r.source))
+	}
+	return info, lang, codeBuf.Bytes()
+}
+
+// indentsCodeBlock reports whether the given code block
+// with the given info string and contents
+// should be written as an indented code block.
+func (r *render) indentsCodeBlock(node ast.Node, info, code []byte) bool {
+	switch r.mr.codeBlockStyle {
+	case CodeBlockIndented:
+		if len(info) > 0 {
+			return false
+		}
+	case CodeBlockPreserve:
+		if node.Kind() != ast.KindCodeBlock {
+			return false
+		}
+	default:
+		return false
+	}
+
+	// Indented code blocks can't be empty,
+	// and leading and trailing blank lines aren't part of them.
+	lines := bytes.Split(bytes.TrimSuffix(code, newLineChar), newLineChar)
+	if len(util.TrimLeftSpace(lines[0])) == 0 || len(util.TrimLeftSpace(lines[len(lines)-1])) == 0 {
+		return false
+	}
+
+	if parent := node.Parent(); parent != nil && startsOnMarkerLine(parent) && parent.FirstChild() == node {
+		return false
+	}
+
+	// Indented code blocks can't interrupt a paragraph.
+	if r.followsParagraphTightly(node) {
+		return false
+	}
+
+	// Indented lines following these blocks continue them.
+	if prev := r.previousSibling(node); prev != nil {
+		switch prev.Kind() {
+		case ast.KindList, extAST.KindFootnote, KindFootnoteDefinition, extAST.KindDefinitionList:
+			return false
+		}
+	}
+	return true
+}
+
+// codeBlockIndent returns the indentation of the contents
+// of the given indented code block relative to the current indentation.
+func (r *render) codeBlockIndent(node ast.Node) []byte {
+	if r.mr.listIndentStyle != ListIndentUniform {
+		return fourSpacesChars
+	}
+
+	// Uniformly indented items indent their contents
+	// beyond the column at which their first line starts.
+	var marker []byte
+	switch parent := node.Parent().(type) {
+	case *ast.ListItem:
+		marker = r.listItemMarkerChars(parent)
+	case *extAST.DefinitionDescription:
+		marker = definitionDescriptionChars
+	}
+	if len(marker) > 0 && len(marker) <= len(fourSpacesChars) {
+		return bytes.Repeat(spaceChar, len(marker))
+	}
+	return fourSpacesChars
+}
+
+// codeFence returns a fence for a code block with the given info string
+// and contents that won't be closed early by a fence inside the contents.
+func (mr *Renderer) codeFence(info, code []byte) []byte {
+	c := mr.codeFenceChar
+	if c == '`' && bytes.IndexByte(info, '`') >= 0 {
+		// Info strings of backtick fences can't contain backticks.
+		c = '~'
+	}
+
+	length := mr.codeFenceLength
+	for len(code) > 0 {
+		line := code
+		if idx := bytes.IndexByte(code, '\n'); idx >= 0 {
+			line, code = code[:idx], code[idx+1:]
+		} else {
+			code = nil
+		}
+
+		if n := closingFenceLength(line, c); n >= length {
+			length = n + 1
+		}
+	}
+	return bytes.Repeat([]byte{c}, length)
+}
+
+// closingFenceLength returns the length of the fence
+// if the given line could close a code block fenced with c,
+// and 0 otherwise.
+func closingFenceLength(line []byte, c byte) int {
+	trimmed := bytes.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return 0
+	}
+
+	n := countLeading(trimmed, c)
+	if len(bytes.TrimSpace(trimmed[n:])) > 0 {
+		return 0
+	}
+	return n
+}
@@ -10,32 +10,174 @@ import (
 	"github.com/yuin/goldmark/ast"
 )
 
-func (r *render) renderHeading(node *ast.Heading) error {
-	underlineHeading := false
-	if r.mr.underlineHeadings {
-		underlineHeading = node.Level <= 2
+// underlinesHeading reports whether the given heading
+// should be written as a setext heading.
+func (r *render) underlinesHeading(node *ast.Heading) bool {
+	// Setext headings can't be empty.
+	if r.headingLevel(node) > 2 || !node.HasChildren() {
+		return false
+	}
+	// The underline would turn a preceding paragraph into the heading.
+	if r.followsParagraphTightly(node) {
+		return false
+	}
+
+	switch r.mr.headingStyle {
+	case HeadingSetext:
+		return true
+	case HeadingPreserve:
+		if isSetextHeading(r.source, node) {
+			return true
+		}
+	}
+	return hasHardLineBreak(node)
+}
+
+// closesHeading reports whether the given ATX heading
+// should be written with a closing sequence.
+func (r *render) closesHeading(node *ast.Heading) bool {
+	switch r.mr.headingStyle {
+	case HeadingATXClosed:
+		return true
+	case HeadingPreserve:
+		return isClosedATXHeading(r.source, node)
+	}
+	return false
+}
+
+// isSetextHeading reports whether the given heading
+// was written as a setext heading in the source.
+// Unlike ATX headings, these have no '#' before their contents.
+func isSetextHeading(source []byte, node *ast.Heading) bool {
+	lines := node.Lines()
+	if lines.Len() == 0 {
+		return false
+	}
+
+	start := lines.At(0).Start
+	lineStart := bytes.LastIndexByte(source[:start], '\n') + 1
+	return bytes.IndexByte(source[lineStart:start], '#') < 0
+}
+
+// isClosedATXHeading reports whether the given heading
+// was written as an ATX heading with a closing sequence in the source.
+func isClosedATXHeading(source []byte, node *ast.Heading) bool {
+	lines := node.Lines()
+	if lines.Len() != 1 || isSetextHeading(source, node) {
+		return false
+	}
+
+	rest := source[lines.At(0).Stop:]
+	if idx := bytes.IndexByte(rest, '\n'); idx >= 0 {
+		rest = rest[:idx]
+	}
+	rest = bytes.TrimLeft(rest, " \t")
+	return len(rest) > 0 && rest[0] == '#'
+}
+
+// hasHardLineBreak reports whether the contents of the given node
+// hold a hard line break.
+func hasHardLineBreak(node ast.Node) bool {
+	var found bool
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if text, ok := n.(*ast.Text); ok && entering && text.HardLineBreak() {
+			found = true
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return found
+}
+
+// headingLevel returns the level at which the given heading is written,
+// after normalization and shifting.
+func (r *render) headingLevel(node *ast.Heading) int {
+	level := node.Level
+	if l, ok := r.headingLevels[node]; ok {
+		level = l
 	}
+	level += r.mr.headingShift
+	switch {
+	case level < 1:
+		return 1
+	case level > 6:
+		return 6
+	}
+	return level
+}
+
+// normalizeHeadingLevels returns new levels for the headings
+// of the given document, so that there is at most one level 1 heading
+// and no heading is more than one level below the one before it.
+//
+// Each heading is placed one level below the closest preceding heading
+// with a lower level in the source.
+// If the first heading is at level 1, it's kept as the title,
+// and all other headings are placed below it.
+// Without one, headings start at level 2.
+func normalizeHeadingLevels(doc ast.Node) map[*ast.Heading]int {
+	type section struct{ source, level int }
+
+	var (
+		levels = make(map[*ast.Heading]int)
+		// Enclosing sections, starting with the title.
+		stack = []section{{source: 0, level: 1}}
+		first = true
+	)
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		if first && heading.Level == 1 {
+			levels[heading] = 1
+			first = false
+			return ast.WalkSkipChildren, nil
+		}
+		first = false
+
+		for len(stack) > 1 && stack[len(stack)-1].source >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		level := stack[len(stack)-1].level + 1
+		levels[heading] = level
+		stack = append(stack, section{source: heading.Level, level: level})
+		return ast.WalkSkipChildren, nil
+	})
+	return levels
+}
+
+func (r *render) renderHeading(node *ast.Heading) error {
+	underlineHeading := r.underlinesHeading(node)
 
 	if !underlineHeading {
-		r.w.Write(bytes.Repeat([]byte{'#'}, node.Level))
-		r.w.Write(spaceChar)
+		r.w.Write(bytes.Repeat([]byte{'#'}, r.headingLevel(node)))
 	}
 
 	var headBuf bytes.Buffer
 	headBuf.Reset()
 
+	// Setext heading contents start on a new line,
+	// so they may need to be escaped like paragraphs.
+	hr := r.inner(&headBuf)
+	if !underlineHeading {
+		hr = r.innerSpan(&headBuf)
+		hr.singleLine = true
+	}
 	for n := node.FirstChild(); n != nil; n = n.NextSibling() {
-		if err := ast.Walk(n, func(inner ast.Node, entering bool) (ast.WalkStatus, error) {
-			if entering {
-				if err := ast.Walk(inner, r.mr.newRender(&headBuf, r.source).renderNode); err != nil {
-					return ast.WalkStop, err
-				}
-			}
-			return ast.WalkSkipChildren, nil
-		}); err != nil {
+		if err := ast.Walk(n, hr.renderNode); err != nil {
 			return err
 		}
 	}
+	if !underlineHeading {
+		content := escapeClosingSequence(headBuf.Bytes())
+		headBuf.Reset()
+		_, _ = headBuf.Write(content)
+		if len(content) > 0 && r.closesHeading(node) {
+			_, _ = headBuf.Write(spaceChar)
+			_, _ = headBuf.Write(bytes.Repeat([]byte{'#'}, r.headingLevel(node)))
+		}
+	}
 	a := node.Attributes()
 	sort.SliceStable(a, func(i, j int) bool {
 		switch {
@@ -52,6 +194,9 @@ func (r *render) renderHeading(node *ast.Heading) error {
 	})
 
 	hAttr := []string{}
+	if _, ok := headingAttributeID(node); !ok && len(r.headingIDs[node]) > 0 {
+		hAttr = append(hAttr, "#"+r.headingIDs[node])
+	}
 	for _, attr := range node.Attributes() {
 		switch string(attr.Name) {
 		case "id":
@@ -70,14 +215,27 @@ func (r *render) renderHeading(node *ast.Heading) error {
 		_, _ = fmt.Fprintf(&headBuf, " {%s}", strings.Join(hAttr, " "))
 	}
 
+	if !underlineHeading && headBuf.Len() > 0 {
+		_, _ = r.w.Write(spaceChar)
+	}
 	_, _ = r.w.Write(headBuf.Bytes())
 
 	if underlineHeading {
-		width := runewidth.StringWidth(headBuf.String())
+		// Underlines span the widest line of multi-line headings.
+		var width int
+		lines := strings.Split(headBuf.String(), "\n")
+		for i, line := range lines {
+			if i < len(lines)-1 {
+				line = strings.TrimSuffix(line, string(r.mr.hardBreakChars()))
+			}
+			if w := runewidth.StringWidth(line); w > width {
+				width = w
+			}
+		}
 
 		_, _ = r.w.Write(newLineChar)
 
-		switch node.Level {
+		switch r.headingLevel(node) {
 		case 1:
 			r.w.Write(bytes.Repeat(heading1UnderlineChar, width))
 		case 2:
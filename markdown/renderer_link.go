@@ -0,0 +1,634 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	extAST "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// linkKind specifies how a link or image was written in the source.
+type linkKind int
+
+const (
+	linkInline    linkKind = iota // [text](destination)
+	linkFull                      // [text][label]
+	linkCollapsed                 // [label][]
+	linkShortcut                  // [label]
+)
+
+// linkSource holds information about a link or image
+// recovered from the source.
+type linkSource struct {
+	kind linkKind
+
+	// Raw label of the reference, if this is a reference link.
+	label []byte
+
+	// Position in the source right after the link.
+	stop int
+}
+
+// findLinkSource recovers how the given link or image was written
+// in the source from the positions of its contents.
+//
+// Returns false if the source could not be determined,
+// for example because the link has no text.
+func findLinkSource(source []byte, node ast.Node, destination, title []byte) (linkSource, bool) {
+	textStop := inlineStop(source, node)
+	if textStop < 0 {
+		return linkSource{}, false
+	}
+
+	// Skip over closing delimiters of the link text, like '*' or '`'.
+	closer := indexUnescaped(source, textStop, ']')
+	if closer < 0 {
+		return linkSource{}, false
+	}
+	pos := closer + 1
+
+	switch {
+	case pos < len(source) && source[pos] == '(':
+		pos = skipSpace(source, pos+1)
+		if pos < len(source) && source[pos] == '<' {
+			pos += len(destination) + 2
+		} else {
+			pos += len(destination)
+		}
+		pos = skipSpace(source, pos)
+		if len(title) > 0 {
+			pos = skipSpace(source, pos+len(title)+2)
+		}
+		if pos >= len(source) || source[pos] != ')' {
+			return linkSource{}, false
+		}
+		return linkSource{kind: linkInline, stop: pos + 1}, true
+
+	case pos < len(source) && source[pos] == '[':
+		end := indexUnescaped(source, pos+1, ']')
+		if end < 0 {
+			return linkSource{}, false
+		}
+		if label := source[pos+1 : end]; !util.IsBlank(label) {
+			return linkSource{kind: linkFull, label: label, stop: end + 1}, true
+		}
+
+		label, ok := shortcutLabel(source, closer)
+		return linkSource{kind: linkCollapsed, label: label, stop: end + 1}, ok
+
+	default:
+		label, ok := shortcutLabel(source, closer)
+		return linkSource{kind: linkShortcut, label: label, stop: pos}, ok
+	}
+}
+
+// inlineStop reports the position in the source right after
+// the contents of the given inline node, or -1 if it's unknown.
+func inlineStop(source []byte, node ast.Node) int {
+	last := node.LastChild()
+	switch n := last.(type) {
+	case nil:
+		return -1
+	case *ast.Text:
+		return n.Segment.Stop
+	case *ast.RawHTML:
+		if n.Segments.Len() == 0 {
+			return -1
+		}
+		return n.Segments.At(n.Segments.Len() - 1).Stop
+	case *ast.Link:
+		src, ok := findLinkSource(source, n, n.Destination, n.Title)
+		if !ok {
+			return -1
+		}
+		return src.stop
+	case *ast.Image:
+		src, ok := findLinkSource(source, n, n.Destination, n.Title)
+		if !ok {
+			return -1
+		}
+		return src.stop
+	case *ast.AutoLink, *ast.String:
+		// Position is not recorded for these.
+		return -1
+	default:
+		return inlineStop(source, n)
+	}
+}
+
+// shortcutLabel returns the link text preceding the given ']'
+// in the source.
+// Link labels cannot contain unescaped brackets
+// so this searches back for the first '['.
+func shortcutLabel(source []byte, closer int) ([]byte, bool) {
+	for i := closer - 1; i >= 0; i-- {
+		if source[i] == '[' && !isEscaped(source, i) {
+			return source[i+1 : closer], true
+		}
+	}
+	return nil, false
+}
+
+// indexUnescaped returns the index of the first c in source at or after
+// start that is not escaped with a backslash.
+func indexUnescaped(source []byte, start int, c byte) int {
+	for i := start; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
+
+// isEscaped reports whether the character at the given index
+// is preceded by an odd number of backslashes.
+func isEscaped(source []byte, idx int) bool {
+	n := 0
+	for i := idx - 1; i >= 0 && source[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func skipSpace(source []byte, pos int) int {
+	for pos < len(source) && util.IsSpace(source[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// linkReferences tracks link reference definitions in a document.
+type linkReferences struct {
+	// Normalized labels of definitions that have been written.
+	written map[string]struct{}
+
+	// Definitions in the document, by normalized label.
+	defined map[string]*LinkReferenceDefinition
+
+	// Definitions that were referenced, in order of first use,
+	// and ones that were collected without being referenced, in source order,
+	// that have not been written yet.
+	pending   []*LinkReferenceDefinition
+	collected []*LinkReferenceDefinition
+	byLabel   map[string]*LinkReferenceDefinition
+
+	// Normalized labels in use in the document,
+	// and the label used for each link target.
+	// These are used to generate labels for converted links.
+	labels     map[string]struct{}
+	targets    map[string][]byte
+	lastNumber int
+}
+
+func newLinkReferences() *linkReferences {
+	return &linkReferences{
+		written: make(map[string]struct{}),
+		defined: make(map[string]*LinkReferenceDefinition),
+		byLabel: make(map[string]*LinkReferenceDefinition),
+		labels:  make(map[string]struct{}),
+		targets: make(map[string][]byte),
+	}
+}
+
+// Reserve records that the given label is in use
+// for the given destination and title.
+func (refs *linkReferences) Reserve(label, destination, title []byte) {
+	refs.labels[util.ToLinkReference(label)] = struct{}{}
+	target := linkTarget(destination, title)
+	if _, ok := refs.targets[target]; !ok {
+		refs.targets[target] = label
+	}
+}
+
+// Label returns the label for a reference to the given destination and title,
+// generating a new one based on the mode if there isn't one already.
+func (refs *linkReferences) Label(mode ReferenceLinkMode, text, destination, title []byte) []byte {
+	if label, ok := refs.targets[linkTarget(destination, title)]; ok {
+		return label
+	}
+
+	var slug []byte
+	if mode == ReferenceLinksSlug {
+		slug = slugify(text)
+	}
+
+	var label []byte
+	for i := 1; ; i++ {
+		switch {
+		case len(slug) == 0:
+			// Fall back to numbers if there's no text for a slug.
+			refs.lastNumber++
+			label = strconv.AppendInt(nil, int64(refs.lastNumber), 10)
+		case i == 1:
+			label = slug
+		default:
+			label = strconv.AppendInt(append(append([]byte(nil), slug...), '-'), int64(i), 10)
+		}
+
+		if _, taken := refs.labels[util.ToLinkReference(label)]; !taken {
+			break
+		}
+	}
+
+	refs.Reserve(label, destination, title)
+	return label
+}
+
+func linkTarget(destination, title []byte) string {
+	return string(destination) + "\x00" + string(title)
+}
+
+// slugify builds a reference label from the given link text
+// by lowercasing it and replacing runs of other characters
+// than letters and digits with '-'.
+func slugify(text []byte) []byte {
+	var (
+		slug []byte
+		dash bool
+	)
+	for _, c := range string(text) {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			dash = len(slug) > 0
+			continue
+		}
+		if dash {
+			slug = append(slug, '-')
+			dash = false
+		}
+		slug = append(slug, string(unicode.ToLower(c))...)
+	}
+	return slug
+}
+
+// collectLinkReferences records the link reference definitions
+// of the document, and reserves the labels of all reference links
+// and definitions.
+func (r *render) collectLinkReferences(node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	switch n := node.(type) {
+	case *LinkReferenceDefinition:
+		label := normalizeLabel(n.Label)
+		key := util.ToLinkReference(label)
+		if _, ok := r.refs.defined[key]; !ok {
+			r.refs.defined[key] = &LinkReferenceDefinition{
+				Label:       label,
+				Destination: n.Destination,
+				Title:       n.Title,
+			}
+		}
+		r.refs.Reserve(label, n.Destination, n.Title)
+	case *ast.Link:
+		if src, ok := findLinkSource(r.source, n, n.Destination, n.Title); ok && src.kind != linkInline {
+			r.refs.Reserve(normalizeLabel(src.label), n.Destination, n.Title)
+		}
+	case *ast.Image:
+		if src, ok := findLinkSource(r.source, n, n.Destination, n.Title); ok && src.kind != linkInline {
+			r.refs.Reserve(normalizeLabel(src.label), n.Destination, n.Title)
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+// Add records a referenced definition to be written later
+// unless one with the same label was already recorded or written.
+// Definitions found in the document are recorded with their own label.
+func (refs *linkReferences) Add(def *LinkReferenceDefinition) {
+	key := util.ToLinkReference(def.Label)
+	if _, ok := refs.written[key]; ok {
+		return
+	}
+	if _, ok := refs.byLabel[key]; ok {
+		return
+	}
+	if defined, ok := refs.defined[key]; ok {
+		def = defined
+	}
+	refs.byLabel[key] = def
+	refs.pending = append(refs.pending, def)
+}
+
+// Collect records a definition found in the document to be written later,
+// after the referenced ones, unless it's referenced or written before that.
+func (refs *linkReferences) Collect(def *LinkReferenceDefinition) {
+	refs.collected = append(refs.collected, def)
+}
+
+// MarkWritten records that a definition with the given label
+// has been written.
+// It reports false if one was already written.
+func (refs *linkReferences) MarkWritten(label []byte) bool {
+	key := util.ToLinkReference(label)
+	if _, ok := refs.written[key]; ok {
+		return false
+	}
+	refs.written[key] = struct{}{}
+	return true
+}
+
+// referenceStyle reports the LinkReferenceStyle in effect.
+// Converting links into references requires retaining them,
+// so this defaults to collecting definitions at the end of the document
+// when links are converted.
+func (mr *Renderer) referenceStyle() LinkReferenceStyle {
+	if mr.linkRefStyle == LinkReferencesInline && mr.refLinkMode != ReferenceLinksNone {
+		return LinkReferencesAtEnd
+	}
+	return mr.linkRefStyle
+}
+
+// hasOutput reports whether any children of the given node
+// produce output.
+func (r *render) hasOutput(node ast.Node) bool {
+	last := node.LastChild()
+	return last != nil && (r.renders(last) || r.previousSibling(last) != nil)
+}
+
+// renders reports whether the given node produces any output.
+func (r *render) renders(node ast.Node) bool {
+	if r.toc.Replaces(node) {
+		return false
+	}
+
+	switch node.Kind() {
+	case KindLinkReferenceDefinition:
+		return r.mr.referenceStyle() == LinkReferencesInPlace
+	case ast.KindTextBlock:
+		// Without the LinkReferenceDefinitions extension,
+		// paragraphs holding only link reference definitions
+		// are replaced with empty text blocks.
+		return node.HasChildren()
+	case KindFootnoteDefinition:
+		return !r.mr.renumberFootnotes
+	case extAST.KindFootnote:
+		return r.rendersFootnote(node.(*extAST.Footnote))
+	case extAST.KindFootnoteList:
+		for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+			if r.renders(child) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// previousSibling returns the closest previous sibling of the given node
+// that produces output, or nil if there isn't one.
+func (r *render) previousSibling(node ast.Node) ast.Node {
+	prev := node.PreviousSibling()
+	for prev != nil && !r.renders(prev) {
+		prev = prev.PreviousSibling()
+	}
+	return prev
+}
+
+// referenceLinkSource reports whether the given link or image
+// should be rendered as a reference link,
+// and if so, how it was written in the source.
+func (r *render) referenceLinkSource(node ast.Node, destination, title []byte) (linkSource, bool) {
+	if r.mr.referenceStyle() == LinkReferencesInline {
+		return linkSource{}, false
+	}
+
+	src, ok := findLinkSource(r.source, node, destination, title)
+	if ok && src.kind != linkInline {
+		return src, true
+	}
+
+	// Inline links are converted into full reference links
+	// with labels generated at render time.
+	return linkSource{kind: linkFull}, r.mr.refLinkMode != ReferenceLinksNone
+}
+
+// renderReferenceLink renders the given link or image as a reference link
+// if it was written as one in the source.
+//
+// Returns false if the link should be rendered inline instead.
+func (r *render) renderReferenceLink(node ast.Node, destination, title []byte) (bool, error) {
+	src, ok := r.referenceLinkSource(node, destination, title)
+	if !ok {
+		return false, nil
+	}
+
+	var textBuf bytes.Buffer
+	ir := r.inner(&textBuf)
+	for n := node.FirstChild(); n != nil; n = n.NextSibling() {
+		if err := ast.Walk(n, ir.renderNode); err != nil {
+			return false, err
+		}
+	}
+
+	label := normalizeLabel(src.label)
+	if src.label == nil {
+		label = r.refs.Label(r.mr.refLinkMode, textBuf.Bytes(), destination, title)
+	}
+	if node.Kind() == ast.KindImage {
+		_, _ = r.w.Write([]byte("!["))
+	} else {
+		_, _ = r.w.Write([]byte("["))
+	}
+	_, _ = r.w.Write(textBuf.Bytes())
+	_, _ = r.w.Write([]byte("]"))
+
+	// Collapsed and shortcut references use the link text as the label.
+	// Fall back to a full reference if reformatting changed the text.
+	sameText := util.ToLinkReference(textBuf.Bytes()) == util.ToLinkReference(src.label)
+	switch {
+	case src.kind == linkShortcut && sameText:
+	case src.kind == linkCollapsed && sameText:
+		_, _ = r.w.Write([]byte("[]"))
+	default:
+		_, _ = fmt.Fprintf(r.w, "[%s]", label)
+	}
+
+	r.refs.Add(&LinkReferenceDefinition{
+		Label:       label,
+		Destination: destination,
+		Title:       title,
+	})
+	return true, nil
+}
+
+// renderLinkReferenceDefinition renders the given definition,
+// or defers it to the end of the document based on the configured style.
+func (r *render) renderLinkReferenceDefinition(node *LinkReferenceDefinition) {
+	def := &LinkReferenceDefinition{
+		Label:       normalizeLabel(node.Label),
+		Destination: node.Destination,
+		Title:       node.Title,
+	}
+
+	switch r.mr.referenceStyle() {
+	case LinkReferencesInPlace:
+		// De-duplicated definitions were already dropped by the parser,
+		// so this should always be written.
+		r.refs.MarkWritten(def.Label)
+		r.writeLinkReferenceDefinition(def)
+	case LinkReferencesAtEnd, LinkReferencesAtSectionEnd:
+		r.refs.Collect(def)
+	}
+}
+
+// writePendingLinkReferences writes definitions that were referenced
+// or collected, but have not been written yet.
+// Referenced definitions are written first, in order of first use,
+// followed by the others in source order,
+// so that formatting the output again doesn't reorder them.
+// If separate is true, they're separated from preceding output
+// with a blank line.
+func (r *render) writePendingLinkReferences(separate bool) {
+	var defs []*LinkReferenceDefinition
+	for _, def := range append(r.refs.pending, r.refs.collected...) {
+		if r.refs.MarkWritten(def.Label) {
+			defs = append(defs, def)
+		}
+	}
+	r.refs.pending = nil
+	r.refs.collected = nil
+	if len(defs) == 0 {
+		return
+	}
+
+	if separate {
+		_, _ = r.w.Write(newLineChar)
+		_, _ = r.w.Write(newLineChar)
+	}
+	for i, def := range defs {
+		if i > 0 {
+			_, _ = r.w.Write(newLineChar)
+		}
+		r.writeLinkReferenceDefinition(def)
+	}
+}
+
+func (r *render) writeLinkReferenceDefinition(def *LinkReferenceDefinition) {
+	_, _ = fmt.Fprintf(r.w, "[%s]: ", def.Label)
+	r.writeLinkTarget(def.Destination, def.Title)
+}
+
+// linkTitleClosers maps the delimiters that may open a link title
+// to the ones that close it.
+var linkTitleClosers = map[rune]byte{
+	'"':  '"',
+	'\'': '\'',
+	'(':  ')',
+}
+
+// writeLinkTarget writes the given raw destination and title
+// of a link, image, or link reference definition,
+// delimiting and escaping them as needed to be read back the same.
+func (r *render) writeLinkTarget(destination, title []byte) {
+	if needsAngleBrackets(destination) {
+		_, _ = r.w.Write([]byte{'<'})
+		_, _ = r.w.Write(escapeUnescaped(destination, "<>"))
+		_, _ = r.w.Write([]byte{'>'})
+	} else {
+		_, _ = r.w.Write(destination)
+	}
+
+	if len(title) == 0 {
+		return
+	}
+
+	open := r.mr.linkTitleQuote
+	if hasTitleDelimiter(title, open) {
+		for _, c := range []byte(`"'(`) {
+			if !hasTitleDelimiter(title, c) {
+				open = c
+				break
+			}
+		}
+	}
+	closer := linkTitleClosers[rune(open)]
+
+	_, _ = r.w.Write([]byte{' ', open})
+	_, _ = r.w.Write(escapeUnescaped(title, string([]byte{open, closer})))
+	_, _ = r.w.Write([]byte{closer})
+}
+
+// needsAngleBrackets reports whether the given raw link destination
+// must be wrapped in angle brackets:
+// if it's empty, starts with '<', or contains spaces, control characters,
+// or unbalanced parentheses.
+//
+//	[foo](my file.md)   ->   [foo](<my file.md>)
+func needsAngleBrackets(destination []byte) bool {
+	if len(destination) == 0 || destination[0] == '<' {
+		return true
+	}
+
+	var depth int
+	for i := 0; i < len(destination); i++ {
+		switch c := destination[i]; {
+		case c == '\\' && i+1 < len(destination) && util.IsPunct(destination[i+1]):
+			i++
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return true
+			}
+		case c <= ' ' || c == 0x7f:
+			return true
+		}
+	}
+	return depth != 0
+}
+
+// hasTitleDelimiter reports whether the given raw link title
+// contains unescaped characters that would end it early
+// if it were delimited with open.
+func hasTitleDelimiter(title []byte, open byte) bool {
+	delims := string([]byte{open, linkTitleClosers[rune(open)]})
+	return len(escapeUnescaped(title, delims)) != len(title)
+}
+
+// normalizeLabel collapses whitespace inside a link label.
+func normalizeLabel(label []byte) []byte {
+	var buf bytes.Buffer
+	_ = writeClean(&buf, bytes.TrimSpace(label))
+	return buf.Bytes()
+}
+
+// renderAutoLink writes the given autolink,
+// wrapped in angle brackets if it was written with them,
+// or if it's an extended autolink and [WithBracketedAutolinks] is used.
+func (r *render) renderAutoLink(node *ast.AutoLink) {
+	label := node.Label(r.source)
+	if !r.mr.bracketAutolinks && !isBracketedAutoLink(r.source, label) {
+		_, _ = r.w.Write(label)
+		return
+	}
+
+	_, _ = r.w.Write([]byte{'<'})
+	// Extended autolinks like "www.example.com" have no scheme.
+	_, _ = r.w.Write(node.URL(r.source))
+	_, _ = r.w.Write([]byte{'>'})
+}
+
+// isBracketedAutoLink reports whether the given autolink label
+// is surrounded by angle brackets in the source.
+//
+// Labels of autolinks are slices of the source,
+// so their position is recovered from their capacity.
+// Extended autolinks can't follow a '<'.
+func isBracketedAutoLink(source, label []byte) bool {
+	start := cap(source) - cap(label)
+	stop := start + len(label)
+	if start <= 0 || stop >= len(source) || !bytes.Equal(source[start:stop], label) {
+		return false
+	}
+	return source[start-1] == '<' && source[stop] == '>'
+}
@@ -0,0 +1,208 @@
+package markdown
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	extAST "github.com/yuin/goldmark/extension/ast"
+)
+
+// bulletListMarkers are the valid markers of bullet list items.
+const bulletListMarkers = "-*+"
+
+func (r *render) listItemMarkerChars(tnode *ast.ListItem) []byte {
+	parList := tnode.Parent().(*ast.List)
+	if parList.IsOrdered() {
+		return append(strconv.AppendInt(nil, int64(r.listItemNumber(tnode)), 10), r.orderedListDelimiter(parList), ' ')
+	}
+	return []byte{r.bulletMarker(parList), spaceChar[0]}
+}
+
+// listItemNumber returns the number with which
+// the given ordered list item should be written.
+func (r *render) listItemNumber(tnode *ast.ListItem) int {
+	parList := tnode.Parent().(*ast.List)
+	cnt := 1
+	if parList.Start != 0 {
+		cnt = parList.Start
+	}
+
+	switch r.mr.listNumbering {
+	case OrderedListSame:
+		return cnt
+	case OrderedListPreserve:
+		if n, ok := sourceListItemNumber(r.source, tnode); ok {
+			return n
+		}
+	}
+
+	s := tnode.PreviousSibling()
+	for s != nil {
+		cnt++
+		s = s.PreviousSibling()
+	}
+	return cnt
+}
+
+// orderedListDelimiter returns the delimiter with which
+// items of the given ordered list should be written.
+func (r *render) orderedListDelimiter(list *ast.List) byte {
+	delim := list.Marker
+	if r.mr.listDelimiter != 0 {
+		delim = r.mr.listDelimiter
+	}
+
+	// Ordered lists are only separated by a change of delimiter.
+	prev, ok := r.previousSibling(list).(*ast.List)
+	if !ok || !prev.IsOrdered() || r.orderedListDelimiter(prev) != delim {
+		return delim
+	}
+	if delim == '.' {
+		return ')'
+	}
+	return '.'
+}
+
+// bulletMarker returns the marker with which items
+// of the given bullet list should be written.
+func (r *render) bulletMarker(list *ast.List) byte {
+	marker := list.Marker
+	if markers := r.mr.bulletMarkers; len(markers) > 0 {
+		depth := 0
+		for n := list.Parent(); n != nil; n = n.Parent() {
+			if l, ok := n.(*ast.List); ok && !l.IsOrdered() {
+				depth++
+			}
+		}
+		marker = markers[depth%len(markers)]
+	}
+
+	// Bullet lists are only separated by a change of marker.
+	prev, ok := r.previousSibling(list).(*ast.List)
+	if !ok || prev.IsOrdered() || r.bulletMarker(prev) != marker {
+		return marker
+	}
+	candidates := string(r.mr.bulletMarkers) + bulletListMarkers
+	for i := 0; i < len(candidates); i++ {
+		if candidates[i] != marker {
+			return candidates[i]
+		}
+	}
+	return marker
+}
+
+// thematicBreak returns the characters with which
+// the given thematic break should be written.
+//
+// A break written on the marker line of a bullet list item
+// must not use the item's marker, or the whole line,
+// like "- - - -", would be read as a single thematic break.
+// A break right after a paragraph must not use '-',
+// or it would turn the paragraph into a setext heading.
+func (r *render) thematicBreak(node *ast.ThematicBreak) []byte {
+	chars := r.mr.thematicBreak
+
+	var avoid byte
+	if r.followsParagraphTightly(node) {
+		avoid = '-'
+	} else if item, ok := node.Parent().(*ast.ListItem); ok && item.FirstChild() == node {
+		if list := item.Parent().(*ast.List); !list.IsOrdered() {
+			avoid = r.bulletMarker(list)
+		}
+	}
+	if chars[0] != avoid {
+		return chars
+	}
+
+	alt := byte('-')
+	if avoid == '-' {
+		alt = '*'
+	}
+	return bytes.ReplaceAll(chars, chars[:1], []byte{alt})
+}
+
+// looseList reports whether the items of the given list
+// should be separated by blank lines.
+func (r *render) looseList(list *ast.List) bool {
+	if list.IsTight {
+		return false
+	}
+	return !r.mr.tightLists || !hasSingleParagraphItems(list)
+}
+
+// hasSingleParagraphItems reports whether every item of the given list
+// holds no more than a single paragraph.
+func hasSingleParagraphItems(list *ast.List) bool {
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		if item.ChildCount() > 1 {
+			return false
+		}
+		if c := item.FirstChild(); c != nil && c.Kind() != ast.KindParagraph && c.Kind() != ast.KindTextBlock {
+			return false
+		}
+	}
+	return true
+}
+
+// separatesTightly reports whether the given block
+// is inside an item of a tight list,
+// and can follow the previous block without a blank line in between.
+func (r *render) separatesTightly(node ast.Node) bool {
+	item, ok := node.Parent().(*ast.ListItem)
+	if !ok || r.looseList(item.Parent().(*ast.List)) {
+		return false
+	}
+
+	// The parser keeps lists tight despite a blank line before tables,
+	// so keep one to set them apart from a preceding paragraph.
+	if node.Kind() == extAST.KindTable && r.followsParagraphTightly(node) {
+		return false
+	}
+	return true
+}
+
+// followsParagraphTightly reports whether the given block
+// is written right after a paragraph inside an item of a tight list,
+// with no blank line in between.
+// Such blocks must not be written in a form
+// that would continue the paragraph instead.
+func (r *render) followsParagraphTightly(node ast.Node) bool {
+	item, ok := node.Parent().(*ast.ListItem)
+	if !ok || r.looseList(item.Parent().(*ast.List)) {
+		return false
+	}
+	prev := r.previousSibling(node)
+	return prev != nil && (prev.Kind() == ast.KindParagraph || prev.Kind() == ast.KindTextBlock)
+}
+
+// renderTaskCheckBox writes the given task list checkbox,
+// followed by a space if any text follows it.
+func (r *render) renderTaskCheckBox(node *extAST.TaskCheckBox) {
+	check := byte(' ')
+	if node.IsChecked {
+		check = r.taskCheckChar(node)
+	}
+
+	_, _ = r.w.Write([]byte{'[', check, ']'})
+	if node.NextSibling() != nil {
+		_, _ = r.w.Write(spaceChar)
+	}
+}
+
+// taskCheckChar returns the character with which
+// the given checked checkbox should be written.
+func (r *render) taskCheckChar(node *extAST.TaskCheckBox) byte {
+	if r.mr.taskCheckChar != 0 {
+		return r.mr.taskCheckChar
+	}
+
+	// Checkboxes start the first line of their block.
+	if lines := node.Parent().Lines(); lines.Len() > 0 {
+		line := lines.At(0)
+		if v := line.Value(r.source); len(v) > 1 && v[0] == '[' && (v[1] == 'x' || v[1] == 'X') {
+			return v[1]
+		}
+	}
+	return 'x'
+}
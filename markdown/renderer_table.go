@@ -32,7 +32,7 @@ func (r *render) renderTable(node *extAST.Table) error {
 					}
 
 					cellBuf.Reset()
-					if err := ast.Walk(tnode, r.mr.newRender(&cellBuf, r.source).renderNode); err != nil {
+					if err := ast.Walk(tnode, r.innerCell(&cellBuf).renderNode); err != nil {
 						return ast.WalkStop, err
 					}
 					width := runewidth.StringWidth(cellBuf.String())
@@ -53,6 +53,10 @@ func (r *render) renderTable(node *extAST.Table) error {
 		}
 	}
 
+	if r.mr.tableMaxWidth > 0 {
+		columnWidths = capColumnWidths(columnWidths, r.mr.tableMaxWidth-r.w.IndentWidth())
+	}
+
 	// Write all according to alignments and width.
 	for n := node.FirstChild(); n != nil; n = n.NextSibling() {
 		if err := ast.Walk(n, func(inner ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -86,6 +90,10 @@ func (r *render) renderTable(node *extAST.Table) error {
 						left, right = tableHeaderAlignColChar, tableHeaderAlignColChar
 					}
 					_, _ = r.w.Write(left)
+					if width == 0 {
+						// Delimiter cells need at least one '-'.
+						width = 1
+					}
 					_, _ = r.w.Write(bytes.Repeat(tableHeaderColChar, width))
 					_, _ = r.w.Write(right)
 				}
@@ -103,12 +111,16 @@ func (r *render) renderTable(node *extAST.Table) error {
 				}
 
 				cellBuf.Reset()
-				if err := ast.Walk(tnode, r.mr.newRender(&cellBuf, r.source).renderNode); err != nil {
+				if err := ast.Walk(tnode, r.innerCell(&cellBuf).renderNode); err != nil {
 					return ast.WalkStop, err
 				}
 
 				_, _ = r.w.Write([]byte("| "))
+				// Cells wider than capped columns are left unpadded.
 				whitespaceWidth := width - runewidth.StringWidth(cellBuf.String())
+				if whitespaceWidth < 0 {
+					whitespaceWidth = 0
+				}
 				switch align {
 				default:
 					fallthrough
@@ -138,3 +150,50 @@ func (r *render) renderTable(node *extAST.Table) error {
 	}
 	return nil
 }
+
+// capColumnWidths returns the widths to which the columns
+// of a table with the given widths are padded,
+// so that the table fits within maxWidth columns if possible.
+//
+// The widest columns are capped at the largest width that fits.
+// If no width fits, all widths are 0, and rows are written without padding.
+func capColumnWidths(widths []int, maxWidth int) []int {
+	// Every column adds a border and two spaces of padding,
+	// and the last one a closing border.
+	budget := maxWidth - 3*len(widths) - 1
+
+	var total, widest int
+	for _, w := range widths {
+		total += w
+		if w > widest {
+			widest = w
+		}
+	}
+	if total <= budget {
+		return widths
+	}
+
+	limit := 0
+	for c := widest - 1; c > 0; c-- {
+		total = 0
+		for _, w := range widths {
+			if w > c {
+				w = c
+			}
+			total += w
+		}
+		if total <= budget {
+			limit = c
+			break
+		}
+	}
+
+	capped := make([]int, len(widths))
+	for i, w := range widths {
+		if w > limit {
+			w = limit
+		}
+		capped[i] = w
+	}
+	return capped
+}
@@ -0,0 +1,147 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/yuin/goldmark/ast"
+)
+
+// wrapBuffer collects the rendered contents of a paragraph
+// along with the positions at which lines may be broken.
+type wrapBuffer struct {
+	bytes.Buffer
+
+	// Offsets into the buffer at which whitespace was dropped.
+	// Contents between two consecutive breaks form one word.
+	breaks []int
+}
+
+// Break records that the contents written so far
+// may be separated from what follows by a space or a newline.
+func (b *wrapBuffer) Break() {
+	b.breaks = append(b.breaks, b.Len())
+}
+
+// Words splits the buffer into words at the recorded breaks,
+// dropping empty words.
+func (b *wrapBuffer) Words() [][]byte {
+	var (
+		words [][]byte
+		start int
+	)
+	buf := b.Bytes()
+	for _, end := range append(b.breaks, len(buf)) {
+		if end > start {
+			words = append(words, buf[start:end])
+		}
+		start = end
+	}
+	return words
+}
+
+// wrapping reports whether text should be written as reflowable words.
+func (r *render) wrapping() bool {
+	return r.wrap != nil && r.noBreak == 0
+}
+
+// writeWrapped writes the given text into the wrap buffer,
+// recording a break in place of every run of whitespace.
+func (r *render) writeWrapped(text []byte) {
+	for len(text) > 0 {
+		idx := bytes.IndexFunc(text, isWrapSpace)
+		if idx < 0 {
+			_, _ = r.w.Write(text)
+			return
+		}
+
+		_, _ = r.w.Write(text[:idx])
+		r.wrap.Break()
+		text = bytes.TrimLeftFunc(text[idx:], isWrapSpace)
+	}
+}
+
+func isWrapSpace(c rune) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// renderWrapped renders the inline contents of a paragraph
+// and writes them out reflowed to the configured line width.
+func (r *render) renderWrapped(node ast.Node) error {
+	var buf wrapBuffer
+	inner := r.inner(&buf)
+	inner.wrap = &buf
+	for n := node.FirstChild(); n != nil; n = n.NextSibling() {
+		if err := ast.Walk(n, inner.renderNode); err != nil {
+			return err
+		}
+	}
+
+	width := r.mr.lineWidth - r.w.IndentWidth()
+	col, lineStart := r.markerOverhang, true
+	r.markerOverhang = 0
+	for _, word := range buf.Words() {
+		// Trailing spaces of hard line breaks take up no room.
+		wordWidth := runewidth.StringWidth(string(bytes.TrimRight(firstLine(word), " ")))
+		if !lineStart {
+			if col+1+wordWidth > width && !startsBlock(word) {
+				_, _ = r.w.Write(newLineChar)
+				col = 0
+			} else {
+				_, _ = r.w.Write(spaceChar)
+				col++
+			}
+		}
+		_, _ = r.w.Write(word)
+
+		if idx := bytes.LastIndexByte(word, '\n'); idx >= 0 {
+			col = runewidth.StringWidth(string(word[idx+1:]))
+			lineStart = idx == len(word)-1
+			continue
+		}
+		col += wordWidth
+		lineStart = false
+	}
+	return nil
+}
+
+func firstLine(b []byte) []byte {
+	if idx := bytes.IndexByte(b, '\n'); idx >= 0 {
+		return b[:idx]
+	}
+	return b
+}
+
+// startsBlock reports whether the given word could be read
+// as the start of a new block if it was placed at the start of a line,
+// which would change the meaning of the reflowed paragraph.
+func startsBlock(word []byte) bool {
+	switch {
+	case len(word) == 0:
+		return false
+	case bytes.HasPrefix(word, []byte("```")),
+		bytes.HasPrefix(word, []byte("~~~")):
+		// Fenced code block.
+		return true
+	case word[0] == '>', word[0] == '<':
+		// Blockquote or HTML block.
+		return true
+	case len(bytes.Trim(word, "#")) == 0:
+		// ATX heading.
+		return len(word) <= 6
+	case len(bytes.Trim(word, "-=")) == 0,
+		len(bytes.Trim(word, "*")) == 0,
+		len(bytes.Trim(word, "_")) == 0 && len(word) >= 3,
+		len(bytes.Trim(word, "+")) == 0 && len(word) == 1:
+		// List item, thematic break, or setext heading underline.
+		return true
+	case len(bytes.Trim(word, "|:-")) == 0 && bytes.IndexByte(word, '-') >= 0:
+		// Table delimiter row.
+		return true
+	}
+
+	// Ordered list item.
+	digits := len(word) - len(bytes.TrimLeft(word, "0123456789"))
+	return digits > 0 && digits <= 9 && len(word) == digits+1 &&
+		(word[digits] == '.' || word[digits] == ')')
+}
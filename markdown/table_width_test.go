@@ -0,0 +1,150 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestTableMaxWidth(t *testing.T) {
+	give := joinLines(
+		"| Name | Link | Notes |",
+		"|:-:|---|--:|",
+		"| foo | https://example.com/foo/bar/baz | first |",
+		"| bar | https://example.com/bar | second |",
+	)
+
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "unlimited",
+			give: give,
+			want: joinLines(
+				"| Name | Link                            | Notes  |",
+				"|:----:|---------------------------------|-------:|",
+				"| foo  | https://example.com/foo/bar/baz |  first |",
+				"| bar  | https://example.com/bar         | second |",
+			),
+		},
+		{
+			desc: "fits",
+			opts: []Option{WithTableMaxWidth(51)},
+			give: give,
+			want: joinLines(
+				"| Name | Link                            | Notes  |",
+				"|:----:|---------------------------------|-------:|",
+				"| foo  | https://example.com/foo/bar/baz |  first |",
+				"| bar  | https://example.com/bar         | second |",
+			),
+		},
+		{
+			desc: "capped",
+			opts: []Option{WithTableMaxWidth(45)},
+			give: give,
+			want: joinLines(
+				"| Name | Link                      | Notes  |",
+				"|:----:|---------------------------|-------:|",
+				"| foo  | https://example.com/foo/bar/baz |  first |",
+				"| bar  | https://example.com/bar   | second |",
+			),
+		},
+		{
+			desc: "capped/all columns",
+			opts: []Option{WithTableMaxWidth(20)},
+			give: give,
+			want: joinLines(
+				"| Name | Link | Notes |",
+				"|:---:|-----|----:|",
+				"| foo | https://example.com/foo/bar/baz | first |",
+				"| bar | https://example.com/bar | second |",
+			),
+		},
+		{
+			desc: "compact",
+			opts: []Option{WithTableMaxWidth(10)},
+			give: give,
+			want: joinLines(
+				"| Name | Link | Notes |",
+				"|:-:|---|--:|",
+				"| foo | https://example.com/foo/bar/baz | first |",
+				"| bar | https://example.com/bar | second |",
+			),
+		},
+		{
+			desc: "compact/empty cells",
+			opts: []Option{WithTableMaxWidth(5)},
+			give: joinLines(
+				"| a | b |",
+				"|:-:|---|",
+				"|   | c |",
+			),
+			want: joinLines(
+				"| a | b |",
+				"|:-:|---|",
+				"|  | c |",
+			),
+		},
+		{
+			desc: "indented",
+			opts: []Option{WithTableMaxWidth(47)},
+			give: joinLines(
+				"> | Name | Link | Notes |",
+				"> |:-:|---|--:|",
+				"> | foo | https://example.com/foo/bar/baz | first |",
+				"> | bar | https://example.com/bar | second |",
+			),
+			want: joinLines(
+				"> | Name | Link                      | Notes  |",
+				"> |:----:|---------------------------|-------:|",
+				"> | foo  | https://example.com/foo/bar/baz |  first |",
+				"> | bar  | https://example.com/bar   | second |",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.Table))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestCapColumnWidths(t *testing.T) {
+	tests := []struct {
+		desc     string
+		widths   []int
+		maxWidth int
+		want     []int
+	}{
+		{"fits", []int{3, 10}, 20, []int{3, 10}},
+		{"widest", []int{3, 10, 5}, 25, []int{3, 7, 5}},
+		{"several", []int{8, 10, 2}, 20, []int{4, 4, 2}},
+		{"compact", []int{8, 10}, 5, []int{0, 0}},
+		{"narrowest", []int{1, 10}, 9, []int{1, 1}},
+		{"empty column", []int{0, 10}, 10, []int{0, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, capColumnWidths(tt.widths, tt.maxWidth))
+		})
+	}
+}
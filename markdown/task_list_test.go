@@ -0,0 +1,119 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestTaskCheckStyle(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "preserve",
+			give: joinLines(
+				"- [x] foo",
+				"- [X] bar",
+				"- [ ] baz",
+			),
+			want: joinLines(
+				"- [x] foo",
+				"- [X] bar",
+				"- [ ] baz",
+			),
+		},
+		{
+			desc: "lowercase",
+			opts: []Option{WithTaskCheckStyle('x')},
+			give: joinLines(
+				"- [x] foo",
+				"- [X] bar",
+				"- [ ] baz",
+			),
+			want: joinLines(
+				"- [x] foo",
+				"- [x] bar",
+				"- [ ] baz",
+			),
+		},
+		{
+			desc: "uppercase",
+			opts: []Option{WithTaskCheckStyle('X')},
+			give: joinLines(
+				"1. [x] foo",
+				"2. [X] bar",
+			),
+			want: joinLines(
+				"1. [X] foo",
+				"2. [X] bar",
+			),
+		},
+		{
+			desc: "invalid",
+			opts: []Option{WithTaskCheckStyle('v')},
+			give: "- [X] foo\n",
+			want: "- [X] foo\n",
+		},
+		{
+			desc: "spacing",
+			give: joinLines(
+				"- [x]   foo",
+				"- [ ]",
+			),
+			want: joinLines(
+				"- [x] foo",
+				"- [ ]",
+			),
+		},
+		{
+			desc: "nested",
+			opts: []Option{WithTaskCheckStyle('x')},
+			give: joinLines(
+				"- [X] foo",
+				"  - [X]  bar",
+				"    - [ ]",
+			),
+			want: joinLines(
+				"- [x] foo",
+				"  - [x] bar",
+				"    - [ ]",
+			),
+		},
+		{
+			desc: "uniform",
+			opts: []Option{WithListIndentStyle(ListIndentUniform)},
+			give: joinLines(
+				"- [X] foo",
+				"  - [x]  bar",
+			),
+			want: joinLines(
+				"- [X] foo",
+				"    - [x] bar",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.TaskList))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
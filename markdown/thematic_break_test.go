@@ -0,0 +1,184 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestThematicBreak(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "default",
+			give: joinLines(
+				"foo",
+				"",
+				"***",
+			),
+			want: joinLines(
+				"foo",
+				"",
+				"---",
+			),
+		},
+		{
+			desc: "stars",
+			opts: []Option{WithThematicBreak("***")},
+			give: joinLines(
+				"foo",
+				"",
+				"---",
+			),
+			want: joinLines(
+				"foo",
+				"",
+				"***",
+			),
+		},
+		{
+			desc: "long",
+			opts: []Option{WithThematicBreak("----------")},
+			give: "* * *\n",
+			want: "----------\n",
+		},
+		{
+			desc: "spaced",
+			opts: []Option{WithThematicBreak("_ _ _")},
+			give: "---\n",
+			want: "_ _ _\n",
+		},
+		{
+			desc: "invalid",
+			opts: []Option{WithThematicBreak("--")},
+			give: "***\n",
+			want: "---\n",
+		},
+		{
+			desc: "after paragraph",
+			give: joinLines(
+				"foo",
+				"***",
+			),
+			want: joinLines(
+				"foo",
+				"",
+				"---",
+			),
+		},
+		{
+			desc: "in tight list",
+			give: joinLines(
+				"- # foo",
+				"  ***",
+			),
+			want: joinLines(
+				"- # foo",
+				"  ---",
+			),
+		},
+		{
+			desc: "in tight list/after paragraph",
+			opts: []Option{WithThematicBreak("- - -")},
+			give: joinLines(
+				"- foo",
+				"  ***",
+			),
+			want: joinLines(
+				"- foo",
+				"  * * *",
+			),
+		},
+		{
+			desc: "list item",
+			give: "- ***\n",
+			want: "- ***\n",
+		},
+		{
+			desc: "list item/stars",
+			opts: []Option{WithThematicBreak("* * *")},
+			give: joinLines(
+				"* ---",
+				"* foo",
+			),
+			want: joinLines(
+				"* - - -",
+				"* foo",
+			),
+		},
+		{
+			desc: "list item/bullet marker",
+			opts: []Option{WithBulletMarker('*')},
+			give: "- ***\n",
+			want: "* ---\n",
+		},
+		{
+			desc: "list item/not first",
+			give: joinLines(
+				"- foo",
+				"",
+				"  ***",
+			),
+			want: joinLines(
+				"- foo",
+				"",
+				"  ---",
+			),
+		},
+		{
+			desc: "ordered list item",
+			give: "1. ***\n",
+			want: "1. ---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestIsThematicBreak(t *testing.T) {
+	tests := []struct {
+		give string
+		want bool
+	}{
+		{"---", true},
+		{"***", true},
+		{"___", true},
+		{"- - -", true},
+		{"*\t*\t*", true},
+		{"----------", true},
+		{"", false},
+		{"--", false},
+		{"===", false},
+		{"+++", false},
+		{" ---", false},
+		{"--- ", false},
+		{"-*-", false},
+		{"--a", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			assert.Equal(t, tt.want, isThematicBreak([]byte(tt.give)))
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestTightLists(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "tight",
+			give: joinLines(
+				"- foo",
+				"- bar",
+			),
+			want: joinLines(
+				"- foo",
+				"- bar",
+			),
+		},
+		{
+			desc: "loose",
+			give: joinLines(
+				"- foo",
+				"- bar",
+				"",
+				"- baz",
+			),
+			want: joinLines(
+				"- foo",
+				"",
+				"- bar",
+				"",
+				"- baz",
+			),
+		},
+		{
+			desc: "loose/blocks in item",
+			give: joinLines(
+				"- foo",
+				"",
+				"  bar",
+				"- baz",
+			),
+			want: joinLines(
+				"- foo",
+				"",
+				"  bar",
+				"",
+				"- baz",
+			),
+		},
+		{
+			desc: "tight/heading",
+			give: joinLines(
+				"- foo",
+				"  # bar",
+				"- # baz",
+				"  qux",
+			),
+			want: joinLines(
+				"- foo",
+				"  # bar",
+				"- # baz",
+				"  qux",
+			),
+		},
+		{
+			desc: "tight/underline heading",
+			opts: []Option{WithUnderlineHeadings()},
+			give: joinLines(
+				"- foo",
+				"  # bar",
+			),
+			want: joinLines(
+				"- foo",
+				"  # bar",
+			),
+		},
+		{
+			desc: "tight/blocks",
+			give: joinLines(
+				"- foo",
+				"  ```",
+				"  bar",
+				"  ```",
+				"  > baz",
+				"- qux",
+			),
+			want: joinLines(
+				"- foo",
+				"  ```",
+				"  bar",
+				"  ```",
+				"  > baz",
+				"- qux",
+			),
+		},
+		{
+			desc: "tight/thematic break",
+			give: joinLines(
+				"- foo",
+				"  ***",
+				"- bar",
+			),
+			want: joinLines(
+				"- foo",
+				"  ***",
+				"- bar",
+			),
+		},
+		{
+			desc: "force tight",
+			opts: []Option{WithTightLists()},
+			give: joinLines(
+				"- foo",
+				"",
+				"- bar",
+				"",
+				"  1. baz",
+				"",
+				"  2. qux",
+			),
+			want: joinLines(
+				"- foo",
+				"",
+				"- bar",
+				"",
+				"  1. baz",
+				"  2. qux",
+			),
+		},
+		{
+			desc: "force tight/empty item",
+			opts: []Option{WithTightLists()},
+			give: joinLines(
+				"- foo",
+				"",
+				"-",
+				"",
+				"- bar",
+			),
+			want: joinLines(
+				"- foo",
+				"- ",
+				"- bar",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestTightLists_RoundTrip(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+	}{
+		{
+			desc: "setext heading",
+			opts: []Option{WithHeadingStyle(HeadingSetext)},
+			give: joinLines(
+				"- a",
+				"  # heading",
+				"- b",
+			),
+		},
+		{
+			desc: "indented code block",
+			opts: []Option{WithCodeBlockStyle(CodeBlockIndented)},
+			give: joinLines(
+				"- a",
+				"  ```",
+				"  code",
+				"  ```",
+				"- c",
+			),
+		},
+		{
+			desc: "thematic break",
+			give: joinLines(
+				"- foo",
+				"  ***",
+				"- bar",
+			),
+		},
+		{
+			desc: "thematic break/spaced",
+			opts: []Option{WithThematicBreak("- - -")},
+			give: joinLines(
+				"* foo",
+				"  ___",
+				"* bar",
+			),
+		},
+		{
+			desc: "table",
+			give: joinLines(
+				"- a",
+				"  | x |",
+				"  |---|",
+				"  | y |",
+				"- c",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.Table))
+	format := func(t *testing.T, opts []Option, src []byte) []byte {
+		renderer := NewRenderer()
+		renderer.AddMarkdownOptions(opts...)
+
+		var buff bytes.Buffer
+		require.NoError(t, renderer.Render(&buff, src, md.Parser().Parse(text.NewReader(src))))
+		return buff.Bytes()
+	}
+	toHTML := func(t *testing.T, src []byte) string {
+		var buff bytes.Buffer
+		require.NoError(t, md.Convert(src, &buff))
+		return buff.String()
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			src := []byte(tt.give)
+			got := format(t, tt.opts, src)
+			assert.Equal(t, toHTML(t, src), toHTML(t, got), "HTML changed:\n%s", got)
+			assert.Equal(t, string(got), string(format(t, tt.opts, got)), "not idempotent")
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Names of the HTML comments that delimit tables of contents.
+const (
+	tocOpenMarker  = "toc"
+	tocCloseMarker = "tocstop"
+)
+
+var tocCloseMarkerChars = []byte("<!-- " + tocCloseMarker + " -->")
+
+// tocMarkers records the markers of tables of contents in a document.
+type tocMarkers struct {
+	doc ast.Node
+
+	// Closing markers of opening markers, or nil if they have none.
+	closers map[ast.Node]ast.Node
+
+	// Nodes between markers and the closing markers themselves,
+	// which are replaced by the table of contents.
+	replaced map[ast.Node]struct{}
+}
+
+// findTOCMarkers finds the markers of tables of contents in the given document.
+// Returns nil if there are none.
+//
+// Closing markers must be siblings of their opening markers.
+func findTOCMarkers(source []byte, doc ast.Node) *tocMarkers {
+	var markers *tocMarkers
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || !isTOCMarker(source, node, tocOpenMarker) {
+			return ast.WalkContinue, nil
+		}
+
+		if markers == nil {
+			markers = &tocMarkers{
+				doc:      doc,
+				closers:  make(map[ast.Node]ast.Node),
+				replaced: make(map[ast.Node]struct{}),
+			}
+		}
+		markers.closers[node] = nil
+		for n := node.NextSibling(); n != nil; n = n.NextSibling() {
+			if isTOCMarker(source, n, tocOpenMarker) {
+				break
+			}
+			if !isTOCMarker(source, n, tocCloseMarker) {
+				continue
+			}
+
+			markers.closers[node] = n
+			for c := node.NextSibling(); c != n.NextSibling(); c = c.NextSibling() {
+				markers.replaced[c] = struct{}{}
+			}
+			break
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	return markers
+}
+
+// Opens reports whether the given node opens a table of contents.
+func (m *tocMarkers) Opens(node ast.Node) bool {
+	if m == nil {
+		return false
+	}
+	_, ok := m.closers[node]
+	return ok
+}
+
+// Replaces reports whether the given node is replaced
+// by a table of contents.
+func (m *tocMarkers) Replaces(node ast.Node) bool {
+	if m == nil {
+		return false
+	}
+	_, ok := m.replaced[node]
+	return ok
+}
+
+// isTOCMarker reports whether the given node is an HTML comment
+// holding only the given name, like "<!-- toc -->".
+func isTOCMarker(source []byte, node ast.Node, name string) bool {
+	html, ok := node.(*ast.HTMLBlock)
+	if !ok || html.HTMLBlockType != ast.HTMLBlockType2 {
+		return false
+	}
+
+	comment := bytes.TrimSpace(htmlBlockContents(source, html))
+	if !bytes.HasPrefix(comment, []byte("<!--")) || !bytes.HasSuffix(comment, []byte("-->")) {
+		return false
+	}
+	comment = bytes.TrimSpace(comment[len("<!--") : len(comment)-len("-->")])
+	return bytes.EqualFold(comment, []byte(name))
+}
+
+// htmlBlockContents returns the contents of the given HTML block
+// without the trailing newline.
+func htmlBlockContents(source []byte, node *ast.HTMLBlock) []byte {
+	var segments []text.Segment
+	for i := 0; i < node.Lines().Len(); i++ {
+		segments = append(segments, node.Lines().At(i))
+	}
+
+	if node.ClosureLine.Len() != 0 {
+		segments = append(segments, node.ClosureLine)
+	}
+
+	var contents []byte
+	for _, s := range segments {
+		contents = append(contents, s.Value(source)...)
+	}
+	return bytes.TrimSuffix(contents, newLineChar)
+}
+
+// tocEntry is a heading listed in a table of contents.
+type tocEntry struct {
+	level int
+	text  []byte
+	id    string
+}
+
+// tocEntries returns the headings listed in tables of contents.
+func (r *render) tocEntries() []tocEntry {
+	minDepth, maxDepth := r.mr.toc.MinDepth, r.mr.toc.MaxDepth
+	if minDepth == 0 {
+		minDepth = 1
+	}
+	if maxDepth == 0 {
+		maxDepth = 6
+	}
+
+	// Link to the IDs written by WithHeadingIDs, if any.
+	ids := r.headingIDs
+	if ids == nil {
+		ids = r.writtenHeadingIDs(r.toc.doc, r.mr.toc.Slugs)
+	}
+
+	var entries []tocEntry
+	for n := r.toc.doc.FirstChild(); n != nil; n = n.NextSibling() {
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			continue
+		}
+
+		level := r.headingLevel(heading)
+		if level < minDepth || level > maxDepth || len(ids[heading]) == 0 {
+			continue
+		}
+		entries = append(entries, tocEntry{
+			level: level,
+			text:  bytes.ReplaceAll(r.writtenHeadingText(heading), newLineChar, spaceChar),
+			id:    ids[heading],
+		})
+	}
+	return entries
+}
+
+// renderTOC writes the table of contents that follows the given marker,
+// and the closing marker after it.
+func (r *render) renderTOC(marker *ast.HTMLBlock) {
+	_, _ = r.w.Write(newLineChar)
+	_, _ = r.w.Write(newLineChar)
+
+	if entries := r.tocEntries(); len(entries) > 0 {
+		r.writeTOCEntries(entries)
+		_, _ = r.w.Write(newLineChar)
+		_, _ = r.w.Write(newLineChar)
+	}
+
+	closer, _ := r.toc.closers[marker].(*ast.HTMLBlock)
+	if closer == nil {
+		_, _ = r.w.Write(tocCloseMarkerChars)
+		return
+	}
+	_, _ = r.w.Write(htmlBlockContents(r.source, closer))
+}
+
+// writeTOCEntries writes the given entries as a list of links,
+// with each entry nested under the closest preceding entry
+// with a lower level.
+func (r *render) writeTOCEntries(entries []tocEntry) {
+	var parents []int
+	for i, entry := range entries {
+		for len(parents) > 0 && parents[len(parents)-1] >= entry.level {
+			parents = parents[:len(parents)-1]
+		}
+		depth := len(parents)
+		parents = append(parents, entry.level)
+
+		if i > 0 {
+			_, _ = r.w.Write(newLineChar)
+		}
+		_, _ = r.w.Write(bytes.Repeat(spaceChar, depth*r.tocIndentWidth()))
+		_, _ = r.w.Write([]byte{r.tocMarker(depth), ' ', '['})
+		_, _ = r.w.Write(escapeLiteral(entry.text))
+		_, _ = r.w.Write([]byte("]("))
+		r.writeLinkTarget([]byte("#"+entry.id), nil)
+		_, _ = r.w.Write([]byte{')'})
+	}
+}
+
+// tocMarker returns the marker of items of a table of contents
+// nested at the given depth.
+func (r *render) tocMarker(depth int) byte {
+	if len(r.mr.bulletMarkers) == 0 {
+		return '-'
+	}
+	return r.mr.bulletMarkers[depth%len(r.mr.bulletMarkers)]
+}
+
+// tocIndentWidth returns the indentation of each level
+// of nested items of a table of contents.
+func (r *render) tocIndentWidth() int {
+	if r.mr.listIndentStyle == ListIndentUniform {
+		return len(fourSpacesChars)
+	}
+	return 2
+}
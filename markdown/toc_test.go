@@ -0,0 +1,278 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestTableOfContents(t *testing.T) {
+	give := joinLines(
+		"# Project",
+		"",
+		"<!-- toc -->",
+		"",
+		"- [Outdated](#outdated)",
+		"",
+		"<!-- tocstop -->",
+		"",
+		"## Installation",
+		"",
+		"### Library & *CLI*",
+		"",
+		"## Usage -- `go test`",
+		"",
+		"## Usage -- `go test`",
+		"",
+		"#### Details {#more}",
+		"",
+		"> ## Quoted",
+	)
+
+	tests := []struct {
+		desc string
+		opts []Option
+		give string
+		want string
+	}{
+		{
+			desc: "default",
+			give: give,
+			want: joinLines(
+				"# Project",
+				"",
+				"<!-- toc -->",
+				"",
+				"- [Project](#project)",
+				"  - [Installation](#installation)",
+				`    - [Library \& CLI](#library--cli)`,
+				"  - [Usage -- go test](#usage----go-test)",
+				"  - [Usage -- go test](#usage----go-test-1)",
+				"    - [Details](#more)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"## Installation",
+				"",
+				"### Library & *CLI*",
+				"",
+				"## Usage -- `go test`",
+				"",
+				"## Usage -- `go test`",
+				"",
+				"#### Details {#more}",
+				"",
+				"> ## Quoted",
+			),
+		},
+		{
+			desc: "depth",
+			opts: []Option{WithTableOfContents(TableOfContents{MinDepth: 2, MaxDepth: 2})},
+			give: give,
+			want: joinLines(
+				"# Project",
+				"",
+				"<!-- toc -->",
+				"",
+				"- [Installation](#installation)",
+				"- [Usage -- go test](#usage----go-test)",
+				"- [Usage -- go test](#usage----go-test-1)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"## Installation",
+				"",
+				"### Library & *CLI*",
+				"",
+				"## Usage -- `go test`",
+				"",
+				"## Usage -- `go test`",
+				"",
+				"#### Details {#more}",
+				"",
+				"> ## Quoted",
+			),
+		},
+		{
+			desc: "gitlab slugs",
+			opts: []Option{
+				WithTableOfContents(TableOfContents{MinDepth: 2, MaxDepth: 2, Slugs: SlugGitLab}),
+				WithBulletMarker('*'),
+			},
+			give: give,
+			want: joinLines(
+				"# Project",
+				"",
+				"<!-- toc -->",
+				"",
+				"* [Installation](#installation)",
+				"* [Usage -- go test](#usage-go-test)",
+				"* [Usage -- go test](#usage-go-test-1)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"## Installation",
+				"",
+				"### Library & *CLI*",
+				"",
+				"## Usage -- `go test`",
+				"",
+				"## Usage -- `go test`",
+				"",
+				"#### Details {#more}",
+				"",
+				"> ## Quoted",
+			),
+		},
+		{
+			desc: "heading ids",
+			opts: []Option{
+				WithTableOfContents(TableOfContents{MinDepth: 2, MaxDepth: 2, Slugs: SlugGitLab}),
+				WithHeadingIDs(),
+			},
+			give: give,
+			want: joinLines(
+				"# Project {#project}",
+				"",
+				"<!-- toc -->",
+				"",
+				"- [Installation](#installation)",
+				"- [Usage -- go test](#usage----go-test)",
+				"- [Usage -- go test](#usage----go-test-1)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"## Installation {#installation}",
+				"",
+				"### Library & *CLI* {#library--cli}",
+				"",
+				"## Usage -- `go test` {#usage----go-test}",
+				"",
+				"## Usage -- `go test` {#usage----go-test-1}",
+				"",
+				"#### Details {#more}",
+				"",
+				"> ## Quoted {#quoted}",
+			),
+		},
+		{
+			desc: "normalized",
+			opts: []Option{WithHeadingNormalization(), WithListIndentStyle(ListIndentUniform)},
+			give: joinLines(
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"",
+				"# Foo",
+				"",
+				"### Bar",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"- [Foo](#foo)",
+				"    - [Bar](#bar)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"# Foo",
+				"",
+				"## Bar",
+			),
+		},
+		{
+			desc: "no closing marker",
+			give: joinLines(
+				"<!-- toc -->",
+				"",
+				"# Foo",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"- [Foo](#foo)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"# Foo",
+			),
+		},
+		{
+			desc: "no headings",
+			give: joinLines(
+				"<!-- toc -->",
+				"",
+				"- [Foo](#foo)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"Foo",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"Foo",
+			),
+		},
+		{
+			desc: "nested marker",
+			give: joinLines(
+				"# Foo",
+				"",
+				"> <!-- TOC -->",
+				">",
+				"> Outdated",
+				">",
+				"> <!-- TOCSTOP -->",
+				"",
+				"## Bar",
+			),
+			want: joinLines(
+				"# Foo",
+				"",
+				"> <!-- TOC -->",
+				">",
+				"> - [Foo](#foo)",
+				">   - [Bar](#bar)",
+				">",
+				"> <!-- TOCSTOP -->",
+				"",
+				"## Bar",
+			),
+		},
+		{
+			desc: "other comments",
+			give: joinLines(
+				"<!-- table of contents -->",
+				"",
+				"# Foo",
+			),
+			want: joinLines(
+				"<!-- table of contents -->",
+				"",
+				"# Foo",
+			),
+		},
+	}
+
+	md := goldmark.New(goldmark.WithParserOptions(parser.WithAttribute()))
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			renderer := NewRenderer()
+			renderer.AddMarkdownOptions(tt.opts...)
+
+			src := []byte(tt.give)
+			node := md.Parser().Parse(text.NewReader(src))
+
+			var buff bytes.Buffer
+			require.NoError(t, renderer.Render(&buff, src, node))
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
@@ -2,6 +2,8 @@ package markdown
 
 import (
 	"io"
+
+	"github.com/mattn/go-runewidth"
 )
 
 // lineIndentWriter wraps io.Writer and adds given indent everytime new line is created .
@@ -26,6 +28,18 @@ func (l *lineIndentWriter) PopIndent() {
 	l.id.Pop()
 }
 
+// IndentWidth reports the display width of the indentation
+// that will be written at the start of each new line.
+func (l *lineIndentWriter) IndentWidth() int {
+	return runewidth.StringWidth(string(l.id.indents))
+}
+
+// AtLineStart reports whether the next write
+// will be at the start of a line.
+func (l *lineIndentWriter) AtLineStart() bool {
+	return l.previousCharWasNewLine && l.WasIndentOnFirstWriteWritten()
+}
+
 func (l *lineIndentWriter) AddIndentOnFirstWrite(add []byte) {
 	l.firstWriteExtraIndent = append(l.firstWriteExtraIndent, add...)
 }
@@ -8,29 +8,99 @@ import (
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/util"
 )
 
 // NewGoldmark builds a new [goldmark.Markdown] object
 // capable of reformatting GitHub Formatted Markdown.
+//
+// Use [WithExtensions], [WithParserOptions], and [WithASTTransformers]
+// alongside the renderer options to customize the parser.
 func NewGoldmark(opts ...markdown.Option) goldmark.Markdown {
 	mr := markdown.NewRenderer()
 	mr.AddMarkdownOptions(opts...)
+
+	var goldmarkOpts []goldmark.Option
+	for _, o := range opts {
+		if gopts, ok := o.(markdown.GoldmarkOptions); ok {
+			goldmarkOpts = append(goldmarkOpts, gopts...)
+		}
+	}
 	extensions := []goldmark.Extender{
 		extension.GFM,
+		markdown.LinkReferenceDefinitions,
+		markdown.FrontMatter,
+		markdown.ListItemNumbers,
 	}
 	parserOptions := []parser.Option{
 		parser.WithAttribute(), // We need this to enable # headers {#custom-ids}.
 	}
 
-	gm := goldmark.New(
+	gm := goldmark.New(append([]goldmark.Option{
 		goldmark.WithExtensions(extensions...),
 		goldmark.WithParserOptions(parserOptions...),
 		goldmark.WithRenderer(mr),
-	)
+	}, goldmarkOpts...)...)
 
 	return gm
 }
 
+// WithDefinitionLists enables PHP Markdown Extra-style definition lists
+// for [NewGoldmark] and [Process].
+//
+//	Term
+//	: Definition of the term.
+func WithDefinitionLists() markdown.Option {
+	return markdown.GoldmarkOptions{
+		goldmark.WithExtensions(extension.DefinitionList),
+	}
+}
+
+// WithFootnotes enables footnotes for [NewGoldmark] and [Process].
+// Footnote definitions are kept at their original position.
+//
+//	Text with a footnote.[^1]
+//
+//	[^1]: The footnote.
+func WithFootnotes() markdown.Option {
+	return markdown.GoldmarkOptions{
+		goldmark.WithExtensions(markdown.FootnoteDefinitions),
+	}
+}
+
+// WithExtensions adds the given goldmark extensions
+// to the parser used by [NewGoldmark] and [Process].
+// The renderer must support the nodes added by these extensions.
+// Use [markdown.WithNodeRenderer] to render custom nodes.
+//
+// Punctuation replaced by goldmark's typographer extension
+// is written back as in the source,
+// unless it's replaced with custom substitutions.
+func WithExtensions(exts ...goldmark.Extender) markdown.Option {
+	return markdown.GoldmarkOptions{
+		goldmark.WithExtensions(exts...),
+	}
+}
+
+// WithParserOptions adds the given options
+// to the parser used by [NewGoldmark] and [Process].
+func WithParserOptions(opts ...parser.Option) markdown.Option {
+	return markdown.GoldmarkOptions{
+		goldmark.WithParserOptions(opts...),
+	}
+}
+
+// WithASTTransformers adds the given AST transformers
+// to the parser used by [NewGoldmark] and [Process].
+// Values must be [parser.ASTTransformer]s.
+//
+//	markdownfmt.WithASTTransformers(
+//		util.Prioritized(myTransformer, 100),
+//	)
+func WithASTTransformers(ts ...util.PrioritizedValue) markdown.Option {
+	return WithParserOptions(parser.WithASTTransformers(ts...))
+}
+
 // Process formats given Markdown.
 func Process(filename string, src []byte, opts ...markdown.Option) ([]byte, error) {
 	text, err := readSource(filename, src)
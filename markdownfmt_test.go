@@ -12,7 +12,11 @@ import (
 	"github.com/Kunde21/markdownfmt/v3/markdown"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
 func TestSame(t *testing.T) {
@@ -66,6 +70,40 @@ func TestSameUnderline(t *testing.T) {
 	}
 }
 
+func TestSameDefinitionLists(t *testing.T) {
+	matches, err := filepath.Glob("testdata/*.same-deflist.md")
+	require.NoError(t, err)
+
+	for _, f := range matches {
+		t.Run(f, func(t *testing.T) {
+			reference, err := os.ReadFile(f)
+			require.NoError(t, err)
+
+			output, err := markdownfmt.Process("", reference, markdownfmt.WithDefinitionLists())
+			require.NoError(t, err)
+
+			assert.Equal(t, string(reference), string(output))
+		})
+	}
+}
+
+func TestSameFootnotes(t *testing.T) {
+	matches, err := filepath.Glob("testdata/*.same-footnote.md")
+	require.NoError(t, err)
+
+	for _, f := range matches {
+		t.Run(f, func(t *testing.T) {
+			reference, err := os.ReadFile(f)
+			require.NoError(t, err)
+
+			output, err := markdownfmt.Process("", reference, markdownfmt.WithFootnotes())
+			require.NoError(t, err)
+
+			assert.Equal(t, string(reference), string(output))
+		})
+	}
+}
+
 func TestDifferent(t *testing.T) {
 	matches, err := filepath.Glob("testdata/*.input.md")
 	require.NoError(t, err)
@@ -176,6 +214,79 @@ func TestCustomCodeFormatter(t *testing.T) {
 	assert.Contains(t, string(output), " replaced contents\n")
 }
 
+func TestProcess_GoldmarkOptions(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []markdown.Option
+		give string
+		want string
+	}{
+		{
+			desc: "extensions",
+			opts: []markdown.Option{
+				markdownfmt.WithExtensions(extension.DefinitionList),
+			},
+			give: "Term\n:   Definition.\n",
+			want: "Term\n: Definition.\n",
+		},
+		{
+			desc: "extensions/typographer",
+			opts: []markdown.Option{
+				markdownfmt.WithExtensions(extension.Typographer),
+			},
+			give: "\"Don't\" -- <<wait>> --- 'really'...\n",
+			want: "\"Don't\" -- <<wait>> --- 'really'...\n",
+		},
+		{
+			desc: "parser options",
+			opts: []markdown.Option{
+				markdownfmt.WithParserOptions(parser.WithAutoHeadingID()),
+			},
+			give: "# Hello, World\n",
+			want: "# Hello, World {#hello-world}\n",
+		},
+		{
+			desc: "AST transformers",
+			opts: []markdown.Option{
+				markdownfmt.WithASTTransformers(
+					util.Prioritized(removeThematicBreaks{}, 100),
+				),
+			},
+			give: "Foo\n\n---\n\nBar\n",
+			want: "Foo\n\nBar\n",
+		},
+		{
+			desc: "with renderer options",
+			opts: []markdown.Option{
+				markdown.WithEmphasisToken('_'),
+				markdownfmt.WithParserOptions(parser.WithAutoHeadingID()),
+			},
+			give: "# *Hello*\n",
+			want: "# _Hello_ {#hello}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			output, err := markdownfmt.Process("", []byte(tt.give), tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(output))
+		})
+	}
+}
+
+type removeThematicBreaks struct{}
+
+func (removeThematicBreaks) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	for node := doc.FirstChild(); node != nil; {
+		next := node.NextSibling()
+		if node.Kind() == ast.KindThematicBreak {
+			doc.RemoveChild(doc, node)
+		}
+		node = next
+	}
+}
+
 func BenchmarkRender(b *testing.B) {
 	inputs, err := filepath.Glob("testdata/*.input.md")
 	require.NoError(b, err)